@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type Options struct {
+	StartTimeout    time.Duration // per component, default 30s
+	ShutdownTimeout time.Duration // for the whole shutdown, default 30s
+	Signals         []os.Signal   // default SIGINT, SIGTERM
+	Logger          *slog.Logger  // default slog.Default()
+}
+
+// App starts registered components in order and stops them in reverse order.
+type App struct {
+	opts       Options
+	components []Component
+	started    []Component
+}
+
+func New(opts Options) *App {
+	if opts.StartTimeout <= 0 {
+		opts.StartTimeout = 30 * time.Second
+	}
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = 30 * time.Second
+	}
+	if len(opts.Signals) == 0 {
+		opts.Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &App{opts: opts}
+}
+
+// Register appends components; they are started in registration order.
+func (a *App) Register(components ...Component) *App {
+	a.components = append(a.components, components...)
+	return a
+}
+
+// Start starts every component in order. On failure the already started ones are stopped.
+func (a *App) Start(ctx context.Context) error {
+	for _, c := range a.components {
+		if err := a.checkDependencies(c); err != nil {
+			a.stopStarted()
+			return err
+		}
+
+		startCtx, cancel := context.WithTimeout(ctx, a.opts.StartTimeout)
+		err := c.Start(startCtx)
+		cancel()
+		if err != nil {
+			a.opts.Logger.Error("COMPONENT_START_FAILED", slog.String("component", c.Name()), slog.String("error", err.Error()))
+			a.stopStarted()
+			return fmt.Errorf("start %s: %w", c.Name(), err)
+		}
+
+		a.started = append(a.started, c)
+		a.opts.Logger.Info("COMPONENT_STARTED", slog.String("component", c.Name()))
+	}
+	return nil
+}
+
+// Stop stops started components in reverse order, collecting every error.
+func (a *App) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(a.started) - 1; i >= 0; i-- {
+		c := a.started[i]
+		if err := c.Stop(ctx); err != nil {
+			a.opts.Logger.Error("COMPONENT_STOP_FAILED", slog.String("component", c.Name()), slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("stop %s: %w", c.Name(), err))
+			continue
+		}
+		a.opts.Logger.Info("COMPONENT_STOPPED", slog.String("component", c.Name()))
+	}
+	a.started = nil
+	return errors.Join(errs...)
+}
+
+// Run starts all components, waits for a shutdown signal, ctx cancellation or a Watcher failure,
+// then gracefully stops everything.
+func (a *App) Run(ctx context.Context) error {
+	ctx, stopSignals := signal.NotifyContext(ctx, a.opts.Signals...)
+	defer stopSignals()
+
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+
+	failed := make(chan error, len(a.started))
+	for _, c := range a.started {
+		if w, ok := c.(Watcher); ok {
+			go func(name string, done <-chan error) {
+				if err, ok := <-done; ok && err != nil {
+					failed <- fmt.Errorf("%s: %w", name, err)
+				}
+			}(c.Name(), w.Done())
+		}
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.opts.Logger.Info("SHUTDOWN_REQUESTED")
+	case runErr = <-failed:
+		a.opts.Logger.Error("COMPONENT_FAILED", slog.String("error", runErr.Error()))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.opts.ShutdownTimeout)
+	defer cancel()
+	return errors.Join(runErr, a.Stop(shutdownCtx))
+}
+
+func (a *App) checkDependencies(c Component) error {
+	d, ok := c.(Dependent)
+	if !ok {
+		return nil
+	}
+	for _, dep := range d.DependsOn() {
+		if !a.isStarted(dep) {
+			return fmt.Errorf("component %s depends on %s which is not started", c.Name(), dep)
+		}
+	}
+	return nil
+}
+
+func (a *App) isStarted(name string) bool {
+	for _, c := range a.started {
+		if c.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) stopStarted() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.opts.ShutdownTimeout)
+	defer cancel()
+	_ = a.Stop(ctx)
+}
@@ -0,0 +1,51 @@
+package app
+
+import "context"
+
+// Component is a piece of infrastructure managed by App.
+// Start must block until the component is ready to be used by the ones registered after it.
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Dependent is implemented by components that require other components to be started first.
+type Dependent interface {
+	DependsOn() []string
+}
+
+// Watcher is implemented by long-running components (e.g. HTTP server) that may fail after Start.
+// A value received from Done stops the whole application.
+type Watcher interface {
+	Done() <-chan error
+}
+
+type funcComponent struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+// NewComponent wraps start/stop functions into a Component. Either function may be nil.
+func NewComponent(name string, start, stop func(ctx context.Context) error) Component {
+	return &funcComponent{name: name, start: start, stop: stop}
+}
+
+func (c *funcComponent) Name() string {
+	return c.name
+}
+
+func (c *funcComponent) Start(ctx context.Context) error {
+	if c.start == nil {
+		return nil
+	}
+	return c.start(ctx)
+}
+
+func (c *funcComponent) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	return c.stop(ctx)
+}
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/config"
+	"github.com/nrf24l01/go-web-utils/pgkit"
+	"github.com/nrf24l01/go-web-utils/rabbitMQ"
+	"github.com/nrf24l01/go-web-utils/redis"
+	"github.com/nrf24l01/go-web-utils/s3util"
+)
+
+// PostgresComponent opens the pgx pool and runs goose migrations when Config.Migrations is set.
+type PostgresComponent struct {
+	Config  *config.PGConfig
+	Options pgkit.Options
+	DB      *pgkit.DB
+}
+
+func Postgres(cfg *config.PGConfig) *PostgresComponent {
+	return &PostgresComponent{Config: cfg}
+}
+
+func (p *PostgresComponent) Name() string {
+	return "postgres"
+}
+
+func (p *PostgresComponent) Start(ctx context.Context) error {
+	db, err := pgkit.NewDBWithOptions(ctx, p.Config, p.Options)
+	if err != nil {
+		return err
+	}
+	if err := db.Pool.Ping(ctx); err != nil {
+		db.SQL.Close()
+		db.Pool.Close()
+		return err
+	}
+	if p.Config.Migrations != "" {
+		if err := pgkit.RunMigrations(db.SQL, p.Config); err != nil {
+			db.SQL.Close()
+			db.Pool.Close()
+			return err
+		}
+	}
+	p.DB = db
+	return nil
+}
+
+func (p *PostgresComponent) Stop(ctx context.Context) error {
+	if p.DB == nil {
+		return nil
+	}
+	err := p.DB.SQL.Close()
+	p.DB.Pool.Close()
+	return err
+}
+
+// RedisComponent connects to Redis.
+type RedisComponent struct {
+	Config  *config.RedisConfig
+	Options redis.Options
+	Client  *redis.RedisClient
+}
+
+func Redis(cfg *config.RedisConfig) *RedisComponent {
+	return &RedisComponent{Config: cfg}
+}
+
+func (r *RedisComponent) Name() string {
+	return "redis"
+}
+
+func (r *RedisComponent) Start(ctx context.Context) error {
+	client, err := redis.Connect(ctx, r.Config, r.Options)
+	if err != nil {
+		return err
+	}
+	r.Client = client
+	return nil
+}
+
+func (r *RedisComponent) Stop(ctx context.Context) error {
+	if r.Client == nil {
+		return nil
+	}
+	return r.Client.Client.Close()
+}
+
+// RabbitMQComponent opens the AMQP connection and channel.
+type RabbitMQComponent struct {
+	Config  *config.RabbitMQConfig
+	Options rabbitMQ.Options
+	MQ      *rabbitMQ.RabbitMQ
+}
+
+func RabbitMQ(cfg *config.RabbitMQConfig) *RabbitMQComponent {
+	return &RabbitMQComponent{Config: cfg}
+}
+
+func (r *RabbitMQComponent) Name() string {
+	return "rabbitmq"
+}
+
+func (r *RabbitMQComponent) Start(ctx context.Context) error {
+	mq, err := rabbitMQ.RegisterRabbitMQWithOptions(r.Config, r.Options)
+	if err != nil {
+		return err
+	}
+	r.MQ = mq
+	return nil
+}
+
+func (r *RabbitMQComponent) Stop(ctx context.Context) error {
+	if r.MQ == nil {
+		return nil
+	}
+	chErr := r.MQ.Channel.Close()
+	return errors.Join(chErr, r.MQ.Conn.Close())
+}
+
+// S3Component creates the object storage client.
+type S3Component struct {
+	Config  *config.S3Config
+	Options s3util.Options
+	Client  *s3util.Client
+}
+
+func S3(cfg *config.S3Config) *S3Component {
+	return &S3Component{Config: cfg}
+}
+
+func (s *S3Component) Name() string {
+	return "s3"
+}
+
+func (s *S3Component) Start(ctx context.Context) error {
+	client, err := s3util.NewWithOptions(*s.Config, s.Options)
+	if err != nil {
+		return err
+	}
+	s.Client = client
+	return nil
+}
+
+func (s *S3Component) Stop(ctx context.Context) error {
+	return nil
+}
+
+// HTTPComponent serves Echo on Address. Stop drains in-flight requests via Echo.Shutdown.
+type HTTPComponent struct {
+	Echo         *echo.Echo
+	Address      string
+	Dependencies []string
+
+	done chan error
+}
+
+func HTTP(e *echo.Echo, address string, dependsOn ...string) *HTTPComponent {
+	return &HTTPComponent{Echo: e, Address: address, Dependencies: dependsOn}
+}
+
+func (h *HTTPComponent) Name() string {
+	return "http"
+}
+
+func (h *HTTPComponent) DependsOn() []string {
+	return h.Dependencies
+}
+
+func (h *HTTPComponent) Start(ctx context.Context) error {
+	h.done = make(chan error, 1)
+	go func() {
+		if err := h.Echo.Start(h.Address); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			h.done <- err
+		}
+		close(h.done)
+	}()
+
+	// Wait until the listener is bound so dependants see a ready server.
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if h.Echo.ListenerAddr() != nil {
+			return nil
+		}
+		select {
+		case err, ok := <-h.done:
+			if !ok {
+				return http.ErrServerClosed
+			}
+			return err
+		case <-ctx.Done():
+			// stop the Echo.Start goroutine, it may still bind the listener after the timeout
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			return errors.Join(ctx.Err(), h.Echo.Shutdown(shutdownCtx))
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *HTTPComponent) Stop(ctx context.Context) error {
+	return h.Echo.Shutdown(ctx)
+}
+
+func (h *HTTPComponent) Done() <-chan error {
+	return h.done
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -21,3 +22,15 @@ func LoadArgon2idConfigFromEnv() *Argon2idConfig {
 	}
 	return config
 }
+
+func (cfg Argon2idConfig) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg Argon2idConfig) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg Argon2idConfig) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
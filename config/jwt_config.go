@@ -2,13 +2,14 @@ package config
 
 import (
 	"log"
+	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type JWTConfig struct {
-	AccessJWTSecret  string `env:"ACCESS_JWT_SECRET"`
-	RefreshJWTSecret string `env:"REFRESH_JWT_SECRET"`
+	AccessJWTSecret  string `env:"ACCESS_JWT_SECRET" secret:"true"`
+	RefreshJWTSecret string `env:"REFRESH_JWT_SECRET" secret:"true"`
 
 	AccessTokenExpiryMinutes  int `env:"ACCESS_TOKEN_EXPIRY_MINUTES" envDefault:"15"`
 	RefreshTokenExpiryMinutes int `env:"REFRESH_TOKEN_EXPIRY_MINUTES" envDefault:"10080"` // 7 days
@@ -21,3 +22,15 @@ func LoadJWTConfigFromEnv() *JWTConfig {
 	}
 	return config
 }
+
+func (cfg JWTConfig) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg JWTConfig) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg JWTConfig) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
@@ -2,19 +2,42 @@ package config
 
 import (
 	"log"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type PGConfig struct {
-	PGHost     string `env:"POSTGRES_HOST"`
+	PGHost     string `env:"POSTGRES_HOST"` // comma-separated for failover, e.g. "pg1:5432,pg2:5432"
 	PGPort     string `env:"POSTGRES_PORT"`
 	PGUser     string `env:"POSTGRES_USER"`
-	PGPassword string `env:"POSTGRES_PASSWORD"`
+	PGPassword string `env:"POSTGRES_PASSWORD" secret:"true"`
 	PGDatabase string `env:"POSTGRES_DB"`
 	PGSSLMode  string `env:"POSTGRES_SSLMODE"`
 	PGTimeZone string `env:"POSTGRES_TIMEZONE"`
 	Migrations string `env:"POSTGRES_MIGRATIONS_DIR"`
+
+	PGTargetSessionAttrs string `env:"POSTGRES_TARGET_SESSION_ATTRS"` // any, read-write, read-only, primary, standby, prefer-standby
+
+	PGSSLRootCert string `env:"POSTGRES_SSLROOTCERT"`
+	PGSSLCert     string `env:"POSTGRES_SSLCERT"`
+	PGSSLKey      string `env:"POSTGRES_SSLKEY"`
+
+	PGApplicationName  string `env:"POSTGRES_APPLICATION_NAME"`
+	PGConnectTimeout   int    `env:"POSTGRES_CONNECT_TIMEOUT"` // in seconds
+	PGSearchPath       string `env:"POSTGRES_SEARCH_PATH"`
+	PGStatementTimeout string `env:"POSTGRES_STATEMENT_TIMEOUT"` // e.g. "30s", "500ms"
+
+	PoolMaxConns          int32         `env:"POSTGRES_POOL_MAX_CONNS"`
+	PoolMinConns          int32         `env:"POSTGRES_POOL_MIN_CONNS"`
+	PoolMaxConnLifetime   time.Duration `env:"POSTGRES_POOL_MAX_CONN_LIFETIME"`
+	PoolMaxConnIdleTime   time.Duration `env:"POSTGRES_POOL_MAX_CONN_IDLE_TIME"`
+	PoolHealthCheckPeriod time.Duration `env:"POSTGRES_POOL_HEALTH_CHECK_PERIOD"`
 }
 
 func LoadPGConfigFromEnv() *PGConfig {
@@ -25,29 +48,88 @@ func LoadPGConfigFromEnv() *PGConfig {
 	return config
 }
 
+func (cfg PGConfig) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg PGConfig) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg PGConfig) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
+
 func (cfg *PGConfig) GetDSN() string {
-	dsn := "postgres://"
-	dsn += cfg.PGUser
+	return cfg.dsnURL().String()
+}
+
+// GetRedactedDSN returns the DSN with the password replaced by RedactedValue, safe to print in logs.
+func (cfg *PGConfig) GetRedactedDSN() string {
+	u := cfg.dsnURL()
+	if _, ok := u.User.Password(); !ok {
+		return u.String()
+	}
+	// url.UserPassword would percent-encode the mask; the escaped user name holds no '@'
+	u.User = url.User(cfg.PGUser)
+	return strings.Replace(u.String(), "@", ":"+RedactedValue+"@", 1)
+}
+
+func (cfg *PGConfig) dsnURL() *url.URL {
+	u := &url.URL{
+		Scheme: "postgres",
+		Host:   cfg.hosts(),
+		Path:   "/" + cfg.PGDatabase,
+	}
 	if cfg.PGPassword != "" {
-		dsn += ":" + cfg.PGPassword
+		u.User = url.UserPassword(cfg.PGUser, cfg.PGPassword)
+	} else if cfg.PGUser != "" {
+		u.User = url.User(cfg.PGUser)
 	}
-	dsn += "@" + cfg.PGHost
-	if cfg.PGPort != "" {
-		dsn += ":" + cfg.PGPort
+
+	params := url.Values{}
+	setParam := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
 	}
-	dsn += "/" + cfg.PGDatabase
-	params := ""
-	if cfg.PGSSLMode != "" {
-		params += "sslmode=" + cfg.PGSSLMode
+	setParam("sslmode", cfg.PGSSLMode)
+	setParam("sslrootcert", cfg.PGSSLRootCert)
+	setParam("sslcert", cfg.PGSSLCert)
+	setParam("sslkey", cfg.PGSSLKey)
+	setParam("timezone", cfg.PGTimeZone)
+	setParam("application_name", cfg.PGApplicationName)
+	if cfg.PGConnectTimeout > 0 {
+		params.Set("connect_timeout", strconv.Itoa(cfg.PGConnectTimeout))
 	}
-	if cfg.PGTimeZone != "" {
-		if params != "" {
-			params += "&"
+	setParam("search_path", cfg.PGSearchPath)
+	setParam("statement_timeout", cfg.PGStatementTimeout)
+	setParam("target_session_attrs", cfg.PGTargetSessionAttrs)
+	u.RawQuery = params.Encode()
+
+	return u
+}
+
+// hosts returns the comma-separated host list, appending PGPort to hosts that have no port.
+func (cfg *PGConfig) hosts() string {
+	parts := strings.Split(cfg.PGHost, ",")
+	out := make([]string, 0, len(parts))
+	for _, h := range parts {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
 		}
-		params += "timezone=" + cfg.PGTimeZone
+		if cfg.PGPort != "" && !hasPort(h) {
+			h = net.JoinHostPort(strings.Trim(h, "[]"), cfg.PGPort)
+		}
+		out = append(out, h)
 	}
-	if params != "" {
-		dsn += "?" + params
+	return strings.Join(out, ",")
+}
+
+func hasPort(host string) bool {
+	if strings.HasPrefix(host, "[") {
+		return strings.Contains(host, "]:")
 	}
-	return dsn
+	return strings.Count(host, ":") == 1
 }
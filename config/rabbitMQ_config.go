@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -10,7 +11,7 @@ type RabbitMQConfig struct {
 	RabbitMQHost     string `env:"RABBITMQ_HOST" envDefault:"localhost"`
 	RabbitMQPort     string `env:"RABBITMQ_PORT" envDefault:"5672"`
 	RabbitMQUser     string `env:"RABBITMQ_USER" envDefault:"guest"`
-	RabbitMQPassword string `env:"RABBITMQ_PASSWORD" envDefault:"guest"`
+	RabbitMQPassword string `env:"RABBITMQ_PASSWORD" envDefault:"guest" secret:"true"`
 	RabbitMQVHost    string `env:"RABBITMQ_VHOST" envDefault:"/"`
 }
 
@@ -21,3 +22,15 @@ func LoadRabbitMQConfigFromEnv() *RabbitMQConfig {
 	}
 	return config
 }
+
+func (cfg RabbitMQConfig) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg RabbitMQConfig) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg RabbitMQConfig) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the value of every field tagged with `secret:"true"`
+// when a config is printed or logged.
+const RedactedValue = "******"
+
+func isSecretField(f reflect.StructField) bool {
+	return f.Tag.Get("secret") == "true"
+}
+
+// redactedFieldValue returns the printable value of a field, masking non-empty secrets.
+func redactedFieldValue(f reflect.StructField, v reflect.Value) interface{} {
+	if isSecretField(f) {
+		if v.IsZero() {
+			return ""
+		}
+		return RedactedValue
+	}
+	return v.Interface()
+}
+
+// redactedString renders a config struct as "Name{Field:value ...}" with secrets masked.
+func redactedString(cfg interface{}) string {
+	val := reflect.Indirect(reflect.ValueOf(cfg))
+	typ := val.Type()
+
+	var b strings.Builder
+	b.WriteString(typ.Name())
+	b.WriteByte('{')
+	sep := ""
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		b.WriteString(sep)
+		sep = " "
+		fmt.Fprintf(&b, "%s:%v", f.Name, redactedFieldValue(f, val.Field(i)))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// redactedLogValue renders a config struct as a slog group with secrets masked.
+func redactedLogValue(cfg interface{}) slog.Value {
+	val := reflect.Indirect(reflect.ValueOf(cfg))
+	typ := val.Type()
+
+	attrs := make([]slog.Attr, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		attrs = append(attrs, slog.Any(f.Name, redactedFieldValue(f, val.Field(i))))
+	}
+	return slog.GroupValue(attrs...)
+}
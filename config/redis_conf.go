@@ -2,13 +2,14 @@ package config
 
 import (
 	"log"
+	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type RedisConfig struct {
 	RedisHost     string `env:"REDIS_HOST" envDefault:"localhost:6379"`
-	RedisPassword string `env:"REDIS_PASSWORD" envDefault:""`
+	RedisPassword string `env:"REDIS_PASSWORD" envDefault:"" secret:"true"`
 	RedisDB       int    `env:"REDIS_DB" envDefault:"0"`
 }
 
@@ -19,3 +20,15 @@ func LoadRedisConfigFromEnv() *RedisConfig {
 	}
 	return config
 }
+
+func (cfg RedisConfig) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg RedisConfig) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg RedisConfig) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
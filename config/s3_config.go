@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -9,7 +10,7 @@ import (
 type S3Config struct {
 	Endpoint  string `env:"S3_ENDPOINT" envDefault:"http://127.0.0.1:9000"`
 	AccessKey string `env:"S3_ACCESS_KEY" envDefault:""`
-	SecretKey string `env:"S3_SECRET_KEY" envDefault:""`
+	SecretKey string `env:"S3_SECRET_KEY" envDefault:"" secret:"true"`
 	UseSSL    bool   `env:"S3_USE_SSL" envDefault:"true"`
 	BaseURL   string `env:"S3_BASE_URL" envDefault:"http://127.0.0.1:9000"`
 }
@@ -21,3 +22,15 @@ func LoadS3ConfigFromEnv() *S3Config {
 	}
 	return config
 }
+
+func (cfg S3Config) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg S3Config) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg S3Config) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
@@ -2,12 +2,13 @@ package config
 
 import (
 	"log"
+	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type TgWebAppConfig struct {
-	TgBotToken          string `env:"TG_BOT_TOKEN" envDefault:""`
+	TgBotToken          string `env:"TG_BOT_TOKEN" envDefault:"" secret:"true"`
 	InitDataExpireHours int    `env:"INIT_DATA_EXPIRE_HOURS" envDefault:"24"`
 }
 
@@ -18,3 +19,15 @@ func LoadTgWebAppConfigFromEnv() *TgWebAppConfig {
 	}
 	return config
 }
+
+func (cfg TgWebAppConfig) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg TgWebAppConfig) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg TgWebAppConfig) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
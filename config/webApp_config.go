@@ -2,13 +2,22 @@ package config
 
 import (
 	"log"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type WebAppConfig struct {
 	AppHost     string `env:"APP_HOST" envDefault:"8080"`
-	AllowOrigin string `env:"ALLOW_ORIGIN" envDefault:"http://127.0.0.1:5137"`
+	AllowOrigin string `env:"ALLOW_ORIGIN" envDefault:"http://127.0.0.1:5137"` // comma-separated list
+
+	AllowCredentials bool          `env:"ALLOW_CREDENTIALS" envDefault:"true"`
+	BodyLimit        string        `env:"BODY_LIMIT" envDefault:"10M"` // echo format: 4K, 10M, 1G
+	ReadTimeout      time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"15s"`
+	WriteTimeout     time.Duration `env:"HTTP_WRITE_TIMEOUT" envDefault:"30s"`
+	IdleTimeout      time.Duration `env:"HTTP_IDLE_TIMEOUT" envDefault:"60s"`
 }
 
 func LoadWebAppConfigFromEnv() *WebAppConfig {
@@ -18,3 +27,34 @@ func LoadWebAppConfigFromEnv() *WebAppConfig {
 	}
 	return config
 }
+
+// Address returns the listen address; a bare port like "8080" becomes ":8080".
+func (cfg *WebAppConfig) Address() string {
+	if strings.Contains(cfg.AppHost, ":") {
+		return cfg.AppHost
+	}
+	return ":" + cfg.AppHost
+}
+
+// AllowOrigins splits AllowOrigin into separate origins.
+func (cfg *WebAppConfig) AllowOrigins() []string {
+	origins := make([]string, 0)
+	for _, o := range strings.Split(cfg.AllowOrigin, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
+		}
+	}
+	return origins
+}
+
+func (cfg WebAppConfig) String() string {
+	return redactedString(cfg)
+}
+
+func (cfg WebAppConfig) GoString() string {
+	return redactedString(cfg)
+}
+
+func (cfg WebAppConfig) LogValue() slog.Value {
+	return redactedLogValue(cfg)
+}
@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+)
+
+// TagsContextKey stores the tags added with Tag for the current response.
+const TagsContextKey = "cacheTags"
+
+// HeaderCache reports HIT or MISS when a Store is configured.
+const HeaderCache = "X-Cache"
+
+const headerETag = "ETag"
+
+// Entry is a cached response.
+type Entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	ETag   string      `json:"etag"`
+}
+
+// Store keeps cached responses. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns nil without error on a miss.
+	Get(ctx context.Context, key string) (*Entry, error)
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error
+	// Invalidate drops every entry stored with any of tags.
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+type Options struct {
+	// Store caches full responses; without it the middleware only adds ETags and answers
+	// If-None-Match with 304, which saves bandwidth but still runs the handler.
+	Store Store
+	// TTL of stored responses, default 1m.
+	TTL time.Duration
+	// VaryHeaders are request headers that select different responses, e.g. "Accept-Language".
+	// They are part of the cache key and sent in the Vary header.
+	VaryHeaders []string
+	// VaryUser keys entries by the "userID" set by JWTMiddleware or TGMiddleware; the middleware
+	// must then run after them. Leave it off only for responses that are the same for every user.
+	VaryUser bool
+	// Tags are added to every stored entry, handlers add more with Tag.
+	Tags []string
+	// Prefix separates caches sharing a store, default "cache". Tags are namespaced by the store,
+	// see NewRedisStore.
+	Prefix string
+	// Skipper excludes requests, e.g. streaming endpoints, since responses are buffered.
+	Skipper func(c echo.Context) bool
+}
+
+// Tag attaches tags to the response being cached so Store.Invalidate can drop it later,
+// e.g. Tag(c, "products") on reads and store.Invalidate(ctx, "products") after writes.
+func Tag(c echo.Context, tags ...string) {
+	existing, _ := c.Get(TagsContextKey).([]string)
+	c.Set(TagsContextKey, append(existing, tags...))
+}
+
+// Middleware adds a strong ETag to successful GET responses, answers a matching If-None-Match with
+// 304 and, with a Store, serves repeated requests from the cache. Responses are buffered, errors,
+// non-200 responses and responses with Set-Cookie or Cache-Control: no-store/private (without
+// VaryUser) are never stored. Handler errors are rendered by the middleware and not returned.
+func Middleware(opts Options) echo.MiddlewareFunc {
+	if opts.TTL <= 0 {
+		opts.TTL = time.Minute
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "cache"
+	}
+	vary := append([]string(nil), opts.VaryHeaders...)
+	if opts.VaryUser {
+		vary = append(vary, echo.HeaderAuthorization)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method != http.MethodGet {
+				return next(c)
+			}
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			resp := c.Response()
+			for _, h := range vary {
+				resp.Header().Add(echo.HeaderVary, h)
+			}
+
+			var key string
+			if opts.Store != nil {
+				key = cacheKey(c, opts)
+				entry, err := opts.Store.Get(ctx, key)
+				if err != nil {
+					middleware.Logger(c).LogAttrs(ctx, slog.LevelWarn, "CACHE_STORE_FAILED",
+						slog.String("error", err.Error()),
+					)
+				}
+				if entry != nil {
+					for name, values := range entry.Header {
+						if _, ok := resp.Header()[name]; !ok {
+							resp.Header()[name] = values
+						}
+					}
+					resp.Header().Set(HeaderCache, "HIT")
+					return writeEntry(c, resp.Writer, entry.Status, entry.ETag, entry.Body)
+				}
+				resp.Header().Set(HeaderCache, "MISS")
+			}
+
+			writer := &bufferingWriter{ResponseWriter: resp.Writer, status: http.StatusOK}
+			resp.Writer = writer
+			err := next(c)
+			if err != nil {
+				// render the error into the buffer so it is sent below; it is handled and not returned
+				c.Error(err)
+			}
+			resp.Writer = writer.ResponseWriter
+
+			status, body := writer.status, writer.buf.Bytes()
+			if err != nil || status != http.StatusOK {
+				resp.Writer.WriteHeader(status)
+				_, writeErr := resp.Writer.Write(body)
+				return writeErr
+			}
+
+			etag := resp.Header().Get(headerETag)
+			if etag == "" {
+				etag = ETag(body)
+				resp.Header().Set(headerETag, etag)
+			}
+
+			if opts.Store != nil && storable(resp.Header(), opts.VaryUser) {
+				header := resp.Header().Clone()
+				header.Del(HeaderCache)
+				entry := &Entry{Status: status, Header: header, Body: body, ETag: etag}
+				tags, _ := c.Get(TagsContextKey).([]string)
+				tags = append(append([]string(nil), opts.Tags...), tags...)
+				if err := opts.Store.Set(ctx, key, entry, opts.TTL, tags); err != nil {
+					middleware.Logger(c).LogAttrs(ctx, slog.LevelWarn, "CACHE_STORE_FAILED",
+						slog.String("error", err.Error()),
+					)
+				}
+			}
+			return writeEntry(c, resp.Writer, status, etag, body)
+		}
+	}
+}
+
+// ETag returns a strong entity tag for body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeEntry sends status and body, or 304 when the request's If-None-Match matches etag.
+func writeEntry(c echo.Context, w http.ResponseWriter, status int, etag string, body []byte) error {
+	resp := c.Response()
+	resp.Header().Set(headerETag, etag)
+	if status == http.StatusOK && etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		resp.Header().Del(echo.HeaderContentType)
+		resp.Header().Del(echo.HeaderContentLength)
+		status, body = http.StatusNotModified, nil
+	}
+	resp.Status = status
+	resp.Committed = true
+	w.WriteHeader(status)
+	n, err := w.Write(body)
+	resp.Size = int64(n)
+	return err
+}
+
+// etagMatches implements the weak comparison RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func storable(h http.Header, varyUser bool) bool {
+	if h.Get(echo.HeaderSetCookie) != "" {
+		return false
+	}
+	cc := strings.ToLower(h.Get(echo.HeaderCacheControl))
+	if strings.Contains(cc, "no-store") || (!varyUser && strings.Contains(cc, "private")) {
+		return false
+	}
+	return true
+}
+
+func cacheKey(c echo.Context, opts Options) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", c.Request().URL.RequestURI())
+	for _, name := range opts.VaryHeaders {
+		fmt.Fprintf(h, "%s=%s\n", strings.ToLower(name), c.Request().Header.Get(name))
+	}
+	if opts.VaryUser {
+		fmt.Fprintf(h, "user=%v\n", c.Get("userID"))
+	}
+	return opts.Prefix + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// bufferingWriter holds the response until the ETag is known.
+type bufferingWriter struct {
+	http.ResponseWriter
+	buf    bytes.Buffer
+	status int
+}
+
+func (w *bufferingWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferingWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultMaxEntries = 10000
+
+// MemoryStore caches responses in process memory, per replica. When MaxEntries is reached expired
+// entries are dropped first, then arbitrary ones.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryItem
+	tags       map[string]map[string]struct{}
+	now        func() time.Time
+}
+
+type memoryItem struct {
+	entry   *Entry
+	expires time.Time
+	tags    []string
+}
+
+// NewMemoryStore keeps at most maxEntries responses, default 10000.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]memoryItem),
+		tags:       make(map[string]map[string]struct{}),
+		now:        time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if s.now().After(item.expires) {
+		s.remove(key)
+		return nil, nil
+	}
+	return item.entry, nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.remove(key)
+	if len(s.entries) >= s.maxEntries {
+		for k, item := range s.entries {
+			if now.After(item.expires) {
+				s.remove(k)
+			}
+		}
+	}
+	for k := range s.entries {
+		if len(s.entries) < s.maxEntries {
+			break
+		}
+		s.remove(k)
+	}
+
+	s.entries[key] = memoryItem{entry: entry, expires: now.Add(ttl), tags: tags}
+	for _, tag := range tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Invalidate(ctx context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			s.remove(key)
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+// remove deletes key and unlinks it from its tags; s.mu must be held.
+func (s *MemoryStore) remove(key string) {
+	item, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	for _, tag := range item.tags {
+		if keys, ok := s.tags[tag]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(s.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	rediskit "github.com/nrf24l01/go-web-utils/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// setScript stores ARGV[1] at KEYS[1] for ARGV[2] ms and adds KEYS[1] to the tag sets KEYS[2..].
+// Tag sets are sorted sets scored by entry expiry: members that expired are pruned on every Set and
+// the set lives as long as its newest entry, so a busy tag does not grow without bound.
+var setScript = goredis.NewScript(`
+local ttl = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+for i = 2, #KEYS do
+	redis.call('ZREMRANGEBYSCORE', KEYS[i], '-inf', now)
+	redis.call('ZADD', KEYS[i], now + ttl, KEYS[1])
+	local last = redis.call('ZRANGE', KEYS[i], -1, -1, 'WITHSCORES')
+	redis.call('PEXPIRE', KEYS[i], tonumber(last[2]) - now)
+end
+return 1
+`)
+
+// invalidateScript deletes every entry in the tag sets KEYS and the sets themselves.
+var invalidateScript = goredis.NewScript(`
+for _, tag in ipairs(KEYS) do
+	for _, key in ipairs(redis.call('ZRANGE', tag, 0, -1)) do
+		redis.call('DEL', key)
+	end
+	redis.call('DEL', tag)
+end
+return 1
+`)
+
+// RedisStore shares cached responses between replicas. Tags are Redis sorted sets of entry keys,
+// so the scripts touch several keys and need a standalone Redis rather than a cluster.
+type RedisStore struct {
+	client    *goredis.Client
+	tagPrefix string
+}
+
+// NewRedisStore keeps tag sets under "<prefix>:tag:", default prefix "cache". Caches sharing a Redis
+// need stores with different prefixes, otherwise invalidating a tag in one drops entries of the other.
+func NewRedisStore(client *rediskit.RedisClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "cache"
+	}
+	return &RedisStore{client: client.Client, tagPrefix: prefix + ":tag:"}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (*Entry, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached response: %w", err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode cached response: %w", err)
+	}
+	return &entry, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration, tags []string) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	keys := append([]string{key}, s.tagKeys(tags)...)
+	if err := setScript.Run(ctx, s.client, keys, data, ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("failed to store cached response: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Invalidate(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := invalidateScript.Run(ctx, s.client, s.tagKeys(tags)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached responses: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = s.tagPrefix + tag
+	}
+	return keys
+}
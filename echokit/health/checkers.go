@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nrf24l01/go-web-utils/config"
+	"github.com/nrf24l01/go-web-utils/pgkit"
+	"github.com/nrf24l01/go-web-utils/rabbitMQ"
+	"github.com/nrf24l01/go-web-utils/redis"
+	"github.com/nrf24l01/go-web-utils/s3util"
+)
+
+// Checker reports whether one dependency is usable; Check returns nil when it is.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type funcChecker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// NewChecker wraps a function into a Checker.
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return &funcChecker{name: name, check: check}
+}
+
+func (c *funcChecker) Name() string {
+	return c.name
+}
+
+func (c *funcChecker) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// Postgres pings the pgx pool.
+func Postgres(db *pgkit.DB) Checker {
+	return NewChecker("postgres", func(ctx context.Context) error {
+		return db.Pool.Ping(ctx)
+	})
+}
+
+// Migrations fails while goose migrations from pg_cfg.Migrations are not applied to db.
+func Migrations(db *pgkit.DB, pg_cfg *config.PGConfig) Checker {
+	return NewChecker("migrations", func(ctx context.Context) error {
+		current, latest, err := pgkit.MigrationVersions(ctx, db.SQL, pg_cfg)
+		if err != nil {
+			return err
+		}
+		if current < latest {
+			return fmt.Errorf("database is at version %d, latest migration is %d", current, latest)
+		}
+		return nil
+	})
+}
+
+// Redis sends PING.
+func Redis(client *redis.RedisClient) Checker {
+	return NewChecker("redis", func(ctx context.Context) error {
+		return client.Client.Ping(ctx).Err()
+	})
+}
+
+// RabbitMQ checks that the AMQP connection and channel are open.
+func RabbitMQ(mq *rabbitMQ.RabbitMQ) Checker {
+	return NewChecker("rabbitmq", func(ctx context.Context) error {
+		if mq.Conn == nil || mq.Conn.IsClosed() {
+			return errors.New("connection is closed")
+		}
+		if mq.Channel == nil || mq.Channel.IsClosed() {
+			return errors.New("channel is closed")
+		}
+		return nil
+	})
+}
+
+// S3Bucket checks that bucket exists, named "s3:<bucket>".
+func S3Bucket(client *s3util.Client, bucket string) Checker {
+	return NewChecker("s3:"+bucket, func(ctx context.Context) error {
+		exists, err := client.BucketExists(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("bucket %q does not exist", bucket)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,206 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/misc/logctx"
+)
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+type CheckResult struct {
+	Status     Status    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs float64   `json:"durationMs"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// Report is the JSON body of the probe endpoints.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type Options struct {
+	// Timeout bounds each check, default 2s.
+	Timeout time.Duration
+	// CacheTTL reuses results so frequent probes from several sources do not hammer
+	// the dependencies, default 2s.
+	CacheTTL time.Duration
+	// LivePath default "/healthz", ReadyPath default "/readyz".
+	LivePath  string
+	ReadyPath string
+}
+
+// Health runs liveness and readiness checks. Readiness checks cover dependencies the service needs
+// to take traffic; liveness checks should only fail when restarting the process helps, so most
+// services register none and /healthz just reports that the process serves HTTP.
+type Health struct {
+	opts Options
+
+	mu    sync.RWMutex
+	live  []*check
+	ready []*check
+}
+
+type check struct {
+	checker Checker
+
+	mu      sync.Mutex
+	result  CheckResult
+	checked bool
+}
+
+func New(opts Options) *Health {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = 2 * time.Second
+	}
+	if opts.LivePath == "" {
+		opts.LivePath = "/healthz"
+	}
+	if opts.ReadyPath == "" {
+		opts.ReadyPath = "/readyz"
+	}
+	return &Health{opts: opts}
+}
+
+// AddReadiness registers checks served on ReadyPath.
+func (h *Health) AddReadiness(checkers ...Checker) *Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range checkers {
+		h.ready = append(h.ready, &check{checker: c})
+	}
+	return h
+}
+
+// AddLiveness registers checks served on LivePath.
+func (h *Health) AddLiveness(checkers ...Checker) *Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range checkers {
+		h.live = append(h.live, &check{checker: c})
+	}
+	return h
+}
+
+// Ready runs the readiness checks.
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.ready
+	h.mu.RUnlock()
+	return h.run(ctx, checks)
+}
+
+// Live runs the liveness checks.
+func (h *Health) Live(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.live
+	h.mu.RUnlock()
+	return h.run(ctx, checks)
+}
+
+func (h *Health) LiveHandler() echo.HandlerFunc {
+	return h.handler(h.Live)
+}
+
+func (h *Health) ReadyHandler() echo.HandlerFunc {
+	return h.handler(h.Ready)
+}
+
+// Serve registers LiveHandler on LivePath and ReadyHandler on ReadyPath.
+func (h *Health) Serve(e *echo.Echo) {
+	e.GET(h.opts.LivePath, h.LiveHandler())
+	e.GET(h.opts.ReadyPath, h.ReadyHandler())
+}
+
+func (h *Health) handler(probe func(ctx context.Context) Report) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report := probe(c.Request().Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return c.JSON(status, report)
+	}
+}
+
+// run executes checks concurrently; results younger than CacheTTL are reused.
+func (h *Health) run(ctx context.Context, checks []*check) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, h.opts)
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		report.Checks[c.checker.Name()] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// run returns the cached result or runs the check. Concurrent callers wait for the same run.
+func (c *check) run(ctx context.Context, opts Options) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.checked && now.Sub(c.result.CheckedAt) < opts.CacheTTL {
+		return c.result
+	}
+
+	// the result is shared, so a caller that gave up must not fail it
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opts.Timeout)
+	defer cancel()
+	err := c.checker.Check(checkCtx)
+
+	result := CheckResult{
+		Status:     StatusOK,
+		DurationMs: float64(time.Since(now).Microseconds()) / 1000,
+		CheckedAt:  now,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	logger := logctx.LoggerFrom(ctx)
+	switch {
+	case err != nil && (!c.checked || c.result.Status == StatusOK):
+		logger.LogAttrs(ctx, slog.LevelWarn, "HEALTH_CHECK_FAILED",
+			slog.String("check", c.checker.Name()),
+			slog.String("error", result.Error),
+		)
+	case err == nil && c.checked && c.result.Status != StatusOK:
+		logger.LogAttrs(ctx, slog.LevelInfo, "HEALTH_CHECK_RECOVERED",
+			slog.String("check", c.checker.Name()),
+		)
+	}
+
+	c.result, c.checked = result, true
+	return result
+}
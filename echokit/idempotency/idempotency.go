@@ -0,0 +1,297 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+)
+
+const (
+	// HeaderReplayed is set on responses served from the store.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	pollInterval = 100 * time.Millisecond
+)
+
+const (
+	IDEMPOTENCY_KEY_MISSING     schemas.ErrorCode = "IDEMPOTENCY_KEY_MISSING"
+	IDEMPOTENCY_KEY_REUSED      schemas.ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	IDEMPOTENCY_KEY_IN_PROGRESS schemas.ErrorCode = "IDEMPOTENCY_KEY_IN_PROGRESS"
+)
+
+// Record is what a Store keeps per key: the request fingerprint and, once completed, the response.
+type Record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Completed   bool        `json:"completed"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Store persists idempotency records. Implementations must be safe across replicas.
+type Store interface {
+	// Acquire atomically creates an in-progress record for key that expires after lockTTL.
+	// If a live record already exists it is returned with acquired == false.
+	Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (rec *Record, acquired bool, err error)
+	// Complete stores the response for an acquired key and keeps it for ttl. It does nothing when the
+	// key is no longer the in-progress record of rec.Fingerprint, e.g. after its lock expired.
+	Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error
+	// Release removes an in-progress record so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+type Options struct {
+	Store Store
+	// Header default "Idempotency-Key".
+	Header string
+	// Required rejects requests without the header with 400; otherwise they pass through untouched.
+	Required bool
+	// TTL keeps completed responses for replay, default 24h.
+	TTL time.Duration
+	// LockTTL bounds how long an in-progress request blocks duplicates if the instance dies, default 1m.
+	LockTTL time.Duration
+	// Wait makes concurrent duplicates wait for the first request and replay its response
+	// instead of getting 409 right away.
+	Wait bool
+	// WaitTimeout default 10s; duplicates still running after it get 409.
+	WaitTimeout time.Duration
+	// Methods default POST and PATCH.
+	Methods []string
+	// Prefix separates stores shared by several services, default "idempotency".
+	Prefix string
+	// ScopeFunc scopes keys so clients cannot replay each other's responses,
+	// default the "userID" set by JWTMiddleware or TGMiddleware.
+	ScopeFunc func(c echo.Context) string
+	Skipper   func(c echo.Context) bool
+}
+
+// Middleware makes retries of unsafe requests carrying an Idempotency-Key safe: the first request runs
+// the handler and its response is stored, later requests with the same key and body get the stored
+// response with Idempotent-Replayed: true. A key reused with a different request is rejected with 422,
+// a duplicate arriving while the first is still running gets 409 (or waits, see Options.Wait).
+// 5xx responses are not stored so the client can retry them. Handler errors are rendered by the
+// middleware and not returned.
+func Middleware(opts Options) echo.MiddlewareFunc {
+	if opts.Store == nil {
+		panic("idempotency: Options.Store is required")
+	}
+	if opts.Header == "" {
+		opts.Header = "Idempotency-Key"
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 24 * time.Hour
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = time.Minute
+	}
+	if opts.WaitTimeout <= 0 {
+		opts.WaitTimeout = 10 * time.Second
+	}
+	if opts.Methods == nil {
+		opts.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "idempotency"
+	}
+	if opts.ScopeFunc == nil {
+		opts.ScopeFunc = func(c echo.Context) string {
+			if id := c.Get("userID"); id != nil {
+				return fmt.Sprint(id)
+			}
+			return ""
+		}
+	}
+	methods := make(map[string]struct{}, len(opts.Methods))
+	for _, m := range opts.Methods {
+		methods[m] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if _, ok := methods[req.Method]; !ok {
+				return next(c)
+			}
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			idemKey := req.Header.Get(opts.Header)
+			if idemKey == "" {
+				if opts.Required {
+					return schemas.WriteError(c, http.StatusBadRequest,
+						schemas.GenError(c, IDEMPOTENCY_KEY_MISSING, opts.Header+" header is required", nil))
+				}
+				return next(c)
+			}
+			if len(idemKey) > maxKeyLength {
+				return schemas.WriteError(c, http.StatusBadRequest,
+					schemas.GenError(c, schemas.BAD_REQUEST, fmt.Sprintf("%s must be at most %d characters", opts.Header, maxKeyLength), nil))
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return fmt.Errorf("failed to read request body: %w", err)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := opts.Prefix + ":" + opts.ScopeFunc(c) + ":" + idemKey
+			fingerprint := requestFingerprint(req, body)
+			ctx := req.Context()
+
+			rec, acquired, err := opts.Store.Acquire(ctx, key, fingerprint, opts.LockTTL)
+			if err == nil && !acquired && opts.Wait && pending(rec, fingerprint) {
+				rec, acquired, err = waitForRecord(ctx, opts, key, fingerprint)
+			}
+			if err != nil {
+				middleware.Logger(c).LogAttrs(ctx, slog.LevelError, "IDEMPOTENCY_STORE_FAILED",
+					slog.String("error", err.Error()),
+				)
+				return schemas.WriteError(c, http.StatusServiceUnavailable,
+					schemas.GenError(c, schemas.ErrorCodeForStatus(http.StatusServiceUnavailable), "Idempotency store unavailable", nil))
+			}
+
+			if !acquired {
+				switch {
+				case rec != nil && rec.Fingerprint != fingerprint:
+					return schemas.WriteError(c, http.StatusUnprocessableEntity,
+						schemas.GenError(c, IDEMPOTENCY_KEY_REUSED, opts.Header+" was already used for a different request", nil))
+				case rec != nil && rec.Completed:
+					return replay(c, rec)
+				default:
+					return schemas.WriteError(c, http.StatusConflict,
+						schemas.GenError(c, IDEMPOTENCY_KEY_IN_PROGRESS, "A request with this "+opts.Header+" is still in progress", nil))
+				}
+			}
+
+			return runAndStore(c, next, opts, key, fingerprint)
+		}
+	}
+}
+
+// waitForRecord polls the store until the in-progress request completes, is released or WaitTimeout passes.
+func waitForRecord(ctx context.Context, opts Options, key, fingerprint string) (*Record, bool, error) {
+	timer := time.NewTimer(opts.WaitTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var rec *Record
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-timer.C:
+			return rec, false, nil
+		case <-ticker.C:
+		}
+		r, acquired, err := opts.Store.Acquire(ctx, key, fingerprint, opts.LockTTL)
+		if err != nil || acquired {
+			return r, acquired, err
+		}
+		rec = r
+		if !pending(rec, fingerprint) {
+			return rec, false, nil
+		}
+	}
+}
+
+// pending reports whether rec belongs to the same request and is still running.
+func pending(rec *Record, fingerprint string) bool {
+	return rec == nil || (!rec.Completed && rec.Fingerprint == fingerprint)
+}
+
+func runAndStore(c echo.Context, next echo.HandlerFunc, opts Options, key, fingerprint string) error {
+	resp := c.Response()
+	writer := &recordingWriter{ResponseWriter: resp.Writer}
+	resp.Writer = writer
+	defer func() { resp.Writer = writer.ResponseWriter }()
+
+	if err := next(c); err != nil {
+		// render the error here so its response is stored; it is handled and not returned
+		c.Error(err)
+	}
+
+	// the client may be gone already, the record must still be settled
+	ctx := context.WithoutCancel(c.Request().Context())
+	if !resp.Committed || resp.Status >= http.StatusInternalServerError {
+		if relErr := opts.Store.Release(ctx, key); relErr != nil {
+			middleware.Logger(c).LogAttrs(ctx, slog.LevelWarn, "IDEMPOTENCY_STORE_FAILED",
+				slog.String("error", relErr.Error()),
+			)
+		}
+		return nil
+	}
+
+	rec := &Record{
+		Fingerprint: fingerprint,
+		Completed:   true,
+		Status:      resp.Status,
+		Header:      resp.Header().Clone(),
+		Body:        writer.buf.Bytes(),
+	}
+	if storeErr := opts.Store.Complete(ctx, key, rec, opts.TTL); storeErr != nil {
+		middleware.Logger(c).LogAttrs(ctx, slog.LevelError, "IDEMPOTENCY_STORE_FAILED",
+			slog.String("error", storeErr.Error()),
+		)
+	}
+	return nil
+}
+
+// replay writes a stored response. Headers already set for this request (trace and request IDs)
+// take precedence over the stored ones.
+func replay(c echo.Context, rec *Record) error {
+	header := c.Response().Header()
+	for name, values := range rec.Header {
+		if _, ok := header[name]; !ok {
+			header[name] = values
+		}
+	}
+	header.Set(HeaderReplayed, "true")
+	c.Response().WriteHeader(rec.Status)
+	_, err := c.Response().Write(rec.Body)
+	return err
+}
+
+// requestFingerprint identifies the request a key was first used with: method, URI and body.
+func requestFingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, req.Method)
+	io.WriteString(h, "\n")
+	io.WriteString(h, req.URL.RequestURI())
+	io.WriteString(h, "\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter passes the response through and keeps a copy of the body.
+type recordingWriter struct {
+	http.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *recordingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
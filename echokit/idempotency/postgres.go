@@ -0,0 +1,129 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nrf24l01/go-web-utils/pgkit"
+)
+
+// PostgresStore keeps records in a table; expired rows are overwritten on reuse and removed by DeleteExpired.
+type PostgresStore struct {
+	db    *pgkit.DB
+	name  string
+	table string // quoted name
+}
+
+// NewPostgresStore uses table, default "idempotency_keys". Create it with EnsureSchema or
+// put PostgresSchema into a goose migration.
+func NewPostgresStore(db *pgkit.DB, table string) *PostgresStore {
+	if table == "" {
+		table = "idempotency_keys"
+	}
+	return &PostgresStore{db: db, name: table, table: pgx.Identifier{table}.Sanitize()}
+}
+
+// PostgresSchema returns the DDL for the store table.
+func PostgresSchema(table string) string {
+	if table == "" {
+		table = "idempotency_keys"
+	}
+	t := pgx.Identifier{table}.Sanitize()
+	idx := pgx.Identifier{table + "_expires_at_idx"}.Sanitize()
+	return `CREATE TABLE IF NOT EXISTS ` + t + ` (
+	key         TEXT PRIMARY KEY,
+	fingerprint TEXT NOT NULL,
+	completed   BOOLEAN NOT NULL DEFAULT FALSE,
+	status      INTEGER NOT NULL DEFAULT 0,
+	header      JSONB,
+	body        BYTEA,
+	expires_at  TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ` + idx + ` ON ` + t + ` (expires_at);`
+}
+
+func (s *PostgresStore) EnsureSchema(ctx context.Context) error {
+	if _, err := s.db.Pool.Exec(ctx, PostgresSchema(s.name)); err != nil {
+		return fmt.Errorf("failed to create idempotency table: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	var inserted string
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO `+s.table+` AS t (key, fingerprint, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint, completed = FALSE, status = 0,
+			header = NULL, body = NULL, expires_at = EXCLUDED.expires_at
+		WHERE t.expires_at < now()
+		RETURNING key`,
+		key, fingerprint, lockTTL.Seconds(),
+	).Scan(&inserted)
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	var (
+		rec    Record
+		header []byte
+	)
+	err = s.db.Pool.QueryRow(ctx,
+		`SELECT fingerprint, completed, status, header, body FROM `+s.table+` WHERE key = $1`,
+		key,
+	).Scan(&rec.Fingerprint, &rec.Completed, &rec.Status, &header, &rec.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// released between the two statements; report it as in progress and let the client retry
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load idempotency record: %w", err)
+	}
+	if len(header) > 0 {
+		if err := json.Unmarshal(header, &rec.Header); err != nil {
+			return nil, false, fmt.Errorf("failed to decode idempotency record: %w", err)
+		}
+	}
+	return &rec, false, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE `+s.table+` SET fingerprint = $2, completed = TRUE, status = $3, header = $4, body = $5,
+			expires_at = now() + make_interval(secs => $6)
+		WHERE key = $1 AND NOT completed AND fingerprint = $2`,
+		key, rec.Fingerprint, rec.Status, string(header), rec.Body, ttl.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	if _, err := s.db.Pool.Exec(ctx, `DELETE FROM `+s.table+` WHERE key = $1 AND NOT completed`, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired records; run it periodically, e.g. from a cron job.
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := s.db.Pool.Exec(ctx, `DELETE FROM `+s.table+` WHERE expires_at < now()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
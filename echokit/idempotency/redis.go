@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	rediskit "github.com/nrf24l01/go-web-utils/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Records are hashes: "state" is stateInProgress or stateCompleted, "fingerprint" identifies the
+// request and "record" holds the JSON of the completed Record.
+const (
+	stateInProgress = "in_progress"
+	stateCompleted  = "completed"
+)
+
+// acquireScript creates KEYS[1] as an in-progress record unless it exists and returns the existing
+// state, fingerprint and record.
+var acquireScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	redis.call('HSET', KEYS[1], 'state', ARGV[1], 'fingerprint', ARGV[2])
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+	return false
+end
+local v = redis.call('HMGET', KEYS[1], 'state', 'fingerprint', 'record')
+return {v[1] or '', v[2] or '', v[3] or ''}
+`)
+
+// releaseScript deletes KEYS[1] only while it is still in progress.
+var releaseScript = goredis.NewScript(`
+if redis.call('HGET', KEYS[1], 'state') == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// completeScript stores the completed record in KEYS[1] only while it is still the in-progress record
+// of the same request, so a request whose lock expired cannot overwrite another request's key.
+var completeScript = goredis.NewScript(`
+local v = redis.call('HMGET', KEYS[1], 'state', 'fingerprint')
+if v[1] ~= ARGV[1] or v[2] ~= ARGV[2] then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'state', ARGV[3], 'record', ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+return 1
+`)
+
+// RedisStore keeps records as hashes that expire on their own.
+type RedisStore struct {
+	client *goredis.Client
+}
+
+func NewRedisStore(client *rediskit.RedisClient) *RedisStore {
+	return &RedisStore{client: client.Client}
+}
+
+func (s *RedisStore) Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	existing, err := acquireScript.Run(ctx, s.client, []string{key}, stateInProgress, fingerprint, lockTTL.Milliseconds()).StringSlice()
+	if errors.Is(err, goredis.Nil) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	state, recFingerprint, data := existing[0], existing[1], existing[2]
+	if state != stateCompleted {
+		return &Record{Fingerprint: recFingerprint}, false, nil
+	}
+	var rec Record
+	if err := json.Unmarshal([]byte(data), &rec); err != nil {
+		return nil, false, fmt.Errorf("failed to decode idempotency record: %w", err)
+	}
+	return &rec, false, nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	err = completeScript.Run(ctx, s.client, []string{key},
+		stateInProgress, rec.Fingerprint, stateCompleted, data, ttl.Milliseconds(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if err := releaseScript.Run(ctx, s.client, []string{key}, stateInProgress).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nrf24l01/go-web-utils/rabbitMQ"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+type metric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+}
+
+func newMetric(name, help string, valueType prometheus.ValueType, constLabels prometheus.Labels) metric {
+	return metric{
+		desc:      prometheus.NewDesc(name, help, nil, constLabels),
+		valueType: valueType,
+	}
+}
+
+func (m metric) collect(ch chan<- prometheus.Metric, value float64) {
+	ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, value)
+}
+
+// PGPoolCollector exposes pgxpool.Pool.Stat() under "pgxpool_*", labelled with the pool name.
+type PGPoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns     metric
+	idleConns         metric
+	constructingConns metric
+	totalConns        metric
+	maxConns          metric
+	acquireCount      metric
+	acquireDuration   metric
+	canceledAcquires  metric
+	emptyAcquires     metric
+	emptyAcquireWait  metric
+	newConns          metric
+	lifetimeDestroyed metric
+	idleTimeDestroyed metric
+}
+
+func NewPGPoolCollector(pool *pgxpool.Pool, name string) *PGPoolCollector {
+	labels := prometheus.Labels{"pool": name}
+	return &PGPoolCollector{
+		pool:              pool,
+		acquiredConns:     newMetric("pgxpool_acquired_conns", "Connections currently acquired from the pool.", prometheus.GaugeValue, labels),
+		idleConns:         newMetric("pgxpool_idle_conns", "Idle connections in the pool.", prometheus.GaugeValue, labels),
+		constructingConns: newMetric("pgxpool_constructing_conns", "Connections being established.", prometheus.GaugeValue, labels),
+		totalConns:        newMetric("pgxpool_total_conns", "Total connections in the pool.", prometheus.GaugeValue, labels),
+		maxConns:          newMetric("pgxpool_max_conns", "Maximum size of the pool.", prometheus.GaugeValue, labels),
+		acquireCount:      newMetric("pgxpool_acquire_total", "Successful connection acquires.", prometheus.CounterValue, labels),
+		acquireDuration:   newMetric("pgxpool_acquire_duration_seconds_total", "Total time spent acquiring connections.", prometheus.CounterValue, labels),
+		canceledAcquires:  newMetric("pgxpool_canceled_acquire_total", "Acquires canceled by their context.", prometheus.CounterValue, labels),
+		emptyAcquires:     newMetric("pgxpool_empty_acquire_total", "Acquires that had to wait for a connection.", prometheus.CounterValue, labels),
+		emptyAcquireWait:  newMetric("pgxpool_empty_acquire_wait_seconds_total", "Total time spent waiting for a connection.", prometheus.CounterValue, labels),
+		newConns:          newMetric("pgxpool_new_conns_total", "Connections opened.", prometheus.CounterValue, labels),
+		lifetimeDestroyed: newMetric("pgxpool_max_lifetime_destroy_total", "Connections closed for exceeding MaxConnLifetime.", prometheus.CounterValue, labels),
+		idleTimeDestroyed: newMetric("pgxpool_max_idle_destroy_total", "Connections closed for exceeding MaxConnIdleTime.", prometheus.CounterValue, labels),
+	}
+}
+
+func (c *PGPoolCollector) metrics() []metric {
+	return []metric{
+		c.acquiredConns, c.idleConns, c.constructingConns, c.totalConns, c.maxConns,
+		c.acquireCount, c.acquireDuration, c.canceledAcquires, c.emptyAcquires, c.emptyAcquireWait,
+		c.newConns, c.lifetimeDestroyed, c.idleTimeDestroyed,
+	}
+}
+
+func (c *PGPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		ch <- m.desc
+	}
+}
+
+func (c *PGPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	c.acquiredConns.collect(ch, float64(s.AcquiredConns()))
+	c.idleConns.collect(ch, float64(s.IdleConns()))
+	c.constructingConns.collect(ch, float64(s.ConstructingConns()))
+	c.totalConns.collect(ch, float64(s.TotalConns()))
+	c.maxConns.collect(ch, float64(s.MaxConns()))
+	c.acquireCount.collect(ch, float64(s.AcquireCount()))
+	c.acquireDuration.collect(ch, s.AcquireDuration().Seconds())
+	c.canceledAcquires.collect(ch, float64(s.CanceledAcquireCount()))
+	c.emptyAcquires.collect(ch, float64(s.EmptyAcquireCount()))
+	c.emptyAcquireWait.collect(ch, s.EmptyAcquireWaitTime().Seconds())
+	c.newConns.collect(ch, float64(s.NewConnsCount()))
+	c.lifetimeDestroyed.collect(ch, float64(s.MaxLifetimeDestroyCount()))
+	c.idleTimeDestroyed.collect(ch, float64(s.MaxIdleDestroyCount()))
+}
+
+// RedisPoolStater is implemented by *redis.Client, *redis.ClusterClient and *redis.Ring.
+type RedisPoolStater interface {
+	PoolStats() *redis.PoolStats
+}
+
+// RedisPoolCollector exposes go-redis pool stats under "redis_pool_*", labelled with the client name.
+type RedisPoolCollector struct {
+	client RedisPoolStater
+
+	hits         metric
+	misses       metric
+	timeouts     metric
+	waits        metric
+	waitDuration metric
+	totalConns   metric
+	idleConns    metric
+	staleConns   metric
+}
+
+func NewRedisPoolCollector(client RedisPoolStater, name string) *RedisPoolCollector {
+	labels := prometheus.Labels{"client": name}
+	return &RedisPoolCollector{
+		client:       client,
+		hits:         newMetric("redis_pool_hits_total", "Times a free connection was found in the pool.", prometheus.CounterValue, labels),
+		misses:       newMetric("redis_pool_misses_total", "Times a free connection was not found in the pool.", prometheus.CounterValue, labels),
+		timeouts:     newMetric("redis_pool_timeouts_total", "Times waiting for a connection timed out.", prometheus.CounterValue, labels),
+		waits:        newMetric("redis_pool_waits_total", "Times a connection was waited for.", prometheus.CounterValue, labels),
+		waitDuration: newMetric("redis_pool_wait_duration_seconds_total", "Total time spent waiting for a connection.", prometheus.CounterValue, labels),
+		totalConns:   newMetric("redis_pool_total_conns", "Total connections in the pool.", prometheus.GaugeValue, labels),
+		idleConns:    newMetric("redis_pool_idle_conns", "Idle connections in the pool.", prometheus.GaugeValue, labels),
+		staleConns:   newMetric("redis_pool_stale_conns_total", "Stale connections removed from the pool.", prometheus.CounterValue, labels),
+	}
+}
+
+func (c *RedisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range []metric{c.hits, c.misses, c.timeouts, c.waits, c.waitDuration, c.totalConns, c.idleConns, c.staleConns} {
+		ch <- m.desc
+	}
+}
+
+func (c *RedisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.client.PoolStats()
+	c.hits.collect(ch, float64(s.Hits))
+	c.misses.collect(ch, float64(s.Misses))
+	c.timeouts.collect(ch, float64(s.Timeouts))
+	c.waits.collect(ch, float64(s.WaitCount))
+	c.waitDuration.collect(ch, float64(s.WaitDurationNs)/1e9)
+	c.totalConns.collect(ch, float64(s.TotalConns))
+	c.idleConns.collect(ch, float64(s.IdleConns))
+	c.staleConns.collect(ch, float64(s.StaleConns))
+}
+
+// AMQPCollector exposes whether the RabbitMQ connection and channel are open (1) or closed (0).
+type AMQPCollector struct {
+	mq *rabbitMQ.RabbitMQ
+
+	connectionOpen metric
+	channelOpen    metric
+}
+
+func NewAMQPCollector(mq *rabbitMQ.RabbitMQ, name string) *AMQPCollector {
+	labels := prometheus.Labels{"connection": name}
+	return &AMQPCollector{
+		mq:             mq,
+		connectionOpen: newMetric("amqp_connection_open", "Whether the AMQP connection is open.", prometheus.GaugeValue, labels),
+		channelOpen:    newMetric("amqp_channel_open", "Whether the AMQP channel is open.", prometheus.GaugeValue, labels),
+	}
+}
+
+func (c *AMQPCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connectionOpen.desc
+	ch <- c.channelOpen.desc
+}
+
+func (c *AMQPCollector) Collect(ch chan<- prometheus.Metric) {
+	c.connectionOpen.collect(ch, boolValue(c.mq.Conn != nil && !c.mq.Conn.IsClosed()))
+	c.channelOpen.collect(ch, boolValue(c.mq.Channel != nil && !c.mq.Channel.IsClosed()))
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests that matched no route, so unknown URLs do not create new series.
+const unmatchedRoute = "unmatched"
+
+type Options struct {
+	// Registry holds the metrics, default a new registry with Go runtime and process collectors.
+	Registry *prometheus.Registry
+	// Namespace prefixes metric names, e.g. "myapp" gives "myapp_http_requests_total".
+	Namespace string
+	// Buckets of the latency histogram in seconds, default prometheus.DefBuckets.
+	Buckets []float64
+	// Skipper excludes requests from metrics, e.g. the /metrics endpoint itself.
+	Skipper func(c echo.Context) bool
+}
+
+// Metrics records HTTP request metrics and serves everything in its registry.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	skipper  func(c echo.Context) bool
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+func New(opts Options) (*Metrics, error) {
+	reg := opts.Registry
+	if reg == nil {
+		reg = prometheus.NewRegistry()
+		if err := reg.Register(collectors.NewGoCollector()); err != nil {
+			return nil, fmt.Errorf("failed to register go collector: %w", err)
+		}
+		if err := reg.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+			return nil, fmt.Errorf("failed to register process collector: %w", err)
+		}
+	}
+	if opts.Buckets == nil {
+		opts.Buckets = prometheus.DefBuckets
+	}
+
+	m := &Metrics{
+		Registry: reg,
+		skipper:  opts.Skipper,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route template, method and status class.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status class.",
+			Buckets:   opts.Buckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: opts.Namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests being served by route template and method.",
+		}, []string{"method", "route"}),
+	}
+	for _, c := range []prometheus.Collector{m.requests, m.duration, m.inFlight} {
+		if err := reg.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register HTTP metrics: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// Register adds collectors such as PGPoolCollector to the registry.
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.Registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Middleware records request count, latency and in-flight requests. Routes are labelled by
+// their template (c.Path(), e.g. "/users/:id"), never by the raw URI.
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if m.skipper != nil && m.skipper(c) {
+				return next(c)
+			}
+
+			method := c.Request().Method
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			inFlight := m.inFlight.WithLabelValues(method, route)
+			inFlight.Inc()
+			// deferred so a panic recovered further out does not leave the gauge raised
+			defer inFlight.Dec()
+			start := time.Now()
+
+			err := next(c)
+
+			status := statusClass(middleware.ResponseStatus(c, err))
+			m.requests.WithLabelValues(method, route, status).Inc()
+			m.duration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry}))
+}
+
+// Serve exposes the metrics at path (usually "/metrics") and excludes that path from request metrics.
+func (m *Metrics) Serve(e *echo.Echo, path string) *echo.Route {
+	skipper := m.skipper
+	m.skipper = func(c echo.Context) bool {
+		return c.Path() == path || (skipper != nil && skipper(c))
+	}
+	return e.GET(path, m.Handler())
+}
+
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
@@ -0,0 +1,288 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/config"
+)
+
+// BodyCaptureContextKey stores the *CapturedExchange read by the request loggers.
+const BodyCaptureContextKey = "bodyCapture"
+
+const (
+	defaultCaptureSize      = 4 << 10
+	capturedJSONUnparseable = "[unparseable or truncated JSON omitted]"
+)
+
+var (
+	DefaultRedactHeaders = []string{echo.HeaderAuthorization, echo.HeaderCookie, echo.HeaderSetCookie, "X-Api-Key"}
+	DefaultRedactFields  = []string{"password", "password_confirmation", "token", "access_token", "refresh_token", "secret"}
+)
+
+type BodyCaptureOptions struct {
+	// MaxSize caps each captured body, default 4KB. Larger bodies are still passed on in full.
+	MaxSize int64
+	// RedactJSONPaths are dot-separated paths such as "user.card.number"; "*" matches any key,
+	// arrays are walked transparently and a single segment ("password") matches at any depth.
+	// Default DefaultRedactFields.
+	RedactJSONPaths []string
+	// RedactHeaders default DefaultRedactHeaders.
+	RedactHeaders []string
+	// RedactFormFields applies to urlencoded bodies, default DefaultRedactFields.
+	RedactFormFields []string
+	// ToggleHeader, when set, captures only requests sending it with a true value (e.g. "X-Debug-Capture: 1").
+	// Without it every request through the middleware is captured. Ignored in Production.
+	ToggleHeader string
+	// Production disables capture except on the route templates (c.Path()) in ProductionRoutes,
+	// regardless of ToggleHeader, so clients cannot switch it on.
+	Production       bool
+	ProductionRoutes []string
+}
+
+// CapturedExchange is the redacted request and response of one request.
+type CapturedExchange struct {
+	RequestHeaders    map[string]string `json:"requestHeaders"`
+	RequestBody       string            `json:"requestBody,omitempty"`
+	RequestTruncated  bool              `json:"requestTruncated,omitempty"`
+	ResponseHeaders   map[string]string `json:"responseHeaders"`
+	ResponseBody      string            `json:"responseBody,omitempty"`
+	ResponseTruncated bool              `json:"responseTruncated,omitempty"`
+}
+
+func (e *CapturedExchange) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Any("request_headers", e.RequestHeaders),
+		slog.String("request_body", e.RequestBody),
+		slog.Any("response_headers", e.ResponseHeaders),
+		slog.String("response_body", e.ResponseBody),
+	}
+	if e.RequestTruncated {
+		attrs = append(attrs, slog.Bool("request_truncated", true))
+	}
+	if e.ResponseTruncated {
+		attrs = append(attrs, slog.Bool("response_truncated", true))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// BodyCaptureMiddleware records request and response bodies (up to MaxSize) and headers with
+// secrets redacted and stores them under BodyCaptureContextKey, where RequestLogger and
+// StructuredRequestLogger pick them up. It must run inside the logger middleware. Handler errors are
+// rendered by the Echo error handler here and not returned.
+func BodyCaptureMiddleware(opts BodyCaptureOptions) echo.MiddlewareFunc {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultCaptureSize
+	}
+	if opts.RedactJSONPaths == nil {
+		opts.RedactJSONPaths = DefaultRedactFields
+	}
+	if opts.RedactHeaders == nil {
+		opts.RedactHeaders = DefaultRedactHeaders
+	}
+	if opts.RedactFormFields == nil {
+		opts.RedactFormFields = DefaultRedactFields
+	}
+	jsonPaths := make([][]string, 0, len(opts.RedactJSONPaths))
+	for _, p := range opts.RedactJSONPaths {
+		jsonPaths = append(jsonPaths, strings.Split(strings.ToLower(p), "."))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !captureEnabled(c, opts) {
+				return next(c)
+			}
+
+			req := c.Request()
+			exchange := &CapturedExchange{RequestHeaders: redactHeaders(req.Header, opts.RedactHeaders)}
+
+			if req.Body != nil && req.Body != http.NoBody {
+				head, err := io.ReadAll(io.LimitReader(req.Body, opts.MaxSize+1))
+				if err != nil {
+					return err
+				}
+				req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), req.Body), Closer: req.Body}
+				exchange.RequestTruncated = int64(len(head)) > opts.MaxSize
+				if exchange.RequestTruncated {
+					head = head[:opts.MaxSize]
+				}
+				exchange.RequestBody = redactBody(req.Header.Get(echo.HeaderContentType), head, exchange.RequestTruncated, jsonPaths, opts.RedactFormFields)
+			}
+
+			resp := c.Response()
+			writer := &captureWriter{ResponseWriter: resp.Writer, max: opts.MaxSize}
+			resp.Writer = writer
+			defer func() { resp.Writer = writer.ResponseWriter }()
+
+			c.Set(BodyCaptureContextKey, exchange)
+			if err := next(c); err != nil {
+				// render the error here so its body is captured; it is handled and not passed further
+				c.Error(err)
+			}
+
+			exchange.ResponseHeaders = redactHeaders(resp.Header(), opts.RedactHeaders)
+			exchange.ResponseTruncated = writer.truncated
+			exchange.ResponseBody = redactBody(resp.Header().Get(echo.HeaderContentType), writer.buf.Bytes(), writer.truncated, jsonPaths, opts.RedactFormFields)
+			return nil
+		}
+	}
+}
+
+func captureEnabled(c echo.Context, opts BodyCaptureOptions) bool {
+	if opts.Production {
+		for _, route := range opts.ProductionRoutes {
+			if c.Path() == route {
+				return true
+			}
+		}
+		return false
+	}
+	if opts.ToggleHeader == "" {
+		return true
+	}
+	switch strings.ToLower(c.Request().Header.Get(opts.ToggleHeader)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+func redactHeaders(h http.Header, redact []string) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		value := strings.Join(values, ", ")
+		for _, r := range redact {
+			if strings.EqualFold(name, r) {
+				value = config.RedactedValue
+				break
+			}
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// redactBody renders a captured body for logs. JSON and urlencoded bodies are parsed so secrets can be
+// masked; JSON that cannot be parsed (e.g. truncated) is dropped rather than logged unredacted.
+func redactBody(contentType string, body []byte, truncated bool, jsonPaths [][]string, formFields []string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		if truncated {
+			return capturedJSONUnparseable
+		}
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return capturedJSONUnparseable
+		}
+		for _, path := range jsonPaths {
+			v = redactJSONPath(v, path, len(path) == 1)
+		}
+		out, err := json.Marshal(v)
+		if err != nil {
+			return capturedJSONUnparseable
+		}
+		return string(out)
+	case mediaType == echo.MIMEApplicationForm:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[unparseable form omitted]"
+		}
+		for name := range values {
+			for _, f := range formFields {
+				if strings.EqualFold(name, f) {
+					values[name] = []string{config.RedactedValue}
+				}
+			}
+		}
+		return values.Encode()
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return "[multipart body omitted]"
+	case strings.HasPrefix(mediaType, "text/"), mediaType == echo.MIMEApplicationXML:
+		return string(body)
+	}
+	return "[" + mediaType + " body omitted]"
+}
+
+// redactJSONPath masks the value at path. With anyDepth the single-segment path is applied at every level.
+func redactJSONPath(v interface{}, path []string, anyDepth bool) interface{} {
+	switch node := v.(type) {
+	case []interface{}:
+		for i, item := range node {
+			node[i] = redactJSONPath(item, path, anyDepth)
+		}
+	case map[string]interface{}:
+		for key, child := range node {
+			if path[0] == "*" || strings.EqualFold(key, path[0]) {
+				if len(path) == 1 {
+					node[key] = config.RedactedValue
+					continue
+				}
+				node[key] = redactJSONPath(child, path[1:], false)
+				continue
+			}
+			if anyDepth {
+				node[key] = redactJSONPath(child, path, true)
+			}
+		}
+	}
+	return v
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureWriter passes the response through and keeps its first max bytes.
+type captureWriter struct {
+	http.ResponseWriter
+	buf       bytes.Buffer
+	max       int64
+	truncated bool
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	if room := w.max - int64(w.buf.Len()); room > 0 {
+		if int64(len(p)) > room {
+			w.buf.Write(p[:room])
+			w.truncated = true
+		} else {
+			w.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		w.truncated = true
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *captureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *captureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
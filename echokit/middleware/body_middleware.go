@@ -1,8 +1,14 @@
 package middleware
 
 import (
+	"encoding"
+	"errors"
+	"fmt"
 	"mime/multipart"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -22,13 +28,69 @@ func MultipartValidationMiddleware(schemaFactory func() interface{}) echo.Middle
 	})
 }
 
-// bindMultipartForm binds multipart form data to struct
+// ConversionError describes a form value that could not be converted to the field type.
+type ConversionError struct {
+	Field string
+	Value string
+	Err   error
+}
+
+func (e ConversionError) Error() string {
+	return fmt.Sprintf("field %s: cannot convert %q: %v", e.Field, e.Value, e.Err)
+}
+
+func (e ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// ConversionErrors is returned by multipart binding when one or more values could not be converted.
+type ConversionErrors []ConversionError
+
+func (e ConversionErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ce := range e {
+		msgs[i] = ce.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindMultipartForm binds multipart form data to struct.
+// Fields are matched by the `form` tag (or the Go field name). Supported types are strings, bools,
+// ints, uints, floats, time.Duration, encoding.TextUnmarshaler (e.g. time.Time, uuid.UUID), pointers
+// and slices of those (from repeated keys), files and nested structs. Untagged nested structs share the
+// parent's keys, tagged ones use "parent.child" keys.
 func bindMultipartForm(c echo.Context, schema interface{}, form *multipart.Form) error {
 	val := reflect.ValueOf(schema)
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("schema must be a non-nil pointer to struct")
+	}
+
+	var errs ConversionErrors
+	bindFormStruct(val.Elem(), form, "", &errs, map[formScope]bool{})
+	if len(errs) > 0 {
+		return errs
 	}
+	return nil
+}
+
+// formScope is a pointer-to-struct type bound under a key prefix.
+type formScope struct {
+	typ    reflect.Type
+	prefix string
+}
+
+// bindFormStruct binds form values into val and reports whether anything was bound.
+// active holds the pointer scopes on the current path so self-referential types terminate.
+func bindFormStruct(val reflect.Value, form *multipart.Form, prefix string, errs *ConversionErrors, active map[formScope]bool) bool {
 	typ := val.Type()
+	bound := false
 
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
@@ -39,33 +101,176 @@ func bindMultipartForm(c echo.Context, schema interface{}, form *multipart.Form)
 			continue
 		}
 
-		// Get form tag
-		formTag := fieldType.Tag.Get("form")
-		if formTag == "" {
-			formTag = fieldType.Name
+		formTag, _, _ := strings.Cut(fieldType.Tag.Get("form"), ",")
+		if formTag == "-" {
+			continue
 		}
+		name := formTag
+		if name == "" {
+			name = fieldType.Name
+		}
+		key := prefix + name
 
 		// Handle file fields
-		if field.Type() == reflect.TypeOf(&multipart.FileHeader{}) {
-			if files, ok := form.File[formTag]; ok && len(files) > 0 {
+		switch field.Type() {
+		case fileHeaderType:
+			if files, ok := form.File[key]; ok && len(files) > 0 {
 				field.Set(reflect.ValueOf(files[0]))
-			} else {
-				println("DEBUG: No file found for field:", fieldType.Name, "form tag:", formTag)
+				bound = true
 			}
-		} else if field.Type() == reflect.TypeOf([]*multipart.FileHeader{}) {
-			if files, ok := form.File[formTag]; ok {
+			continue
+		case fileHeaderSliceType:
+			if files, ok := form.File[key]; ok {
 				field.Set(reflect.ValueOf(files))
+				bound = true
 			}
-		} else {
-			// Handle regular form values
-			if values, ok := form.Value[formTag]; ok && len(values) > 0 {
-				// Set string field
-				if field.Kind() == reflect.String {
-					field.SetString(values[0])
-				}
+			continue
+		}
+
+		if values, ok := form.Value[key]; ok && len(values) > 0 {
+			if value, err := setFormField(field, values); err != nil {
+				*errs = append(*errs, ConversionError{Field: key, Value: value, Err: err})
 			}
+			bound = true
+			continue
+		}
+
+		// Handle nested structs
+		structType := derefType(field.Type())
+		if structType.Kind() != reflect.Struct || isTextUnmarshaler(field.Type()) {
+			continue
+		}
+		nestedPrefix := prefix
+		if formTag != "" {
+			nestedPrefix = key + "."
+		}
+		if field.Kind() == reflect.Ptr {
+			// allocate only when the form has keys for it and the scope is not being bound already
+			scope := formScope{typ: structType, prefix: nestedPrefix}
+			if active[scope] || !hasFormPrefix(form, nestedPrefix) {
+				continue
+			}
+			active[scope] = true
+			nested := reflect.New(structType)
+			if bindFormStruct(nested.Elem(), form, nestedPrefix, errs, active) {
+				field.Set(nested)
+				bound = true
+			}
+			delete(active, scope)
+		} else if bindFormStruct(field, form, nestedPrefix, errs, active) {
+			bound = true
 		}
 	}
 
+	return bound
+}
+
+// hasFormPrefix reports whether the form has a value or file key starting with prefix.
+func hasFormPrefix(form *multipart.Form, prefix string) bool {
+	for key := range form.Value {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	for key := range form.File {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	t = derefType(t)
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setFormField converts values into field; slices take every value, other types the first one.
+// On failure it returns the value that could not be converted.
+func setFormField(field reflect.Value, values []string) (string, error) {
+	if field.Kind() == reflect.Slice && !isTextUnmarshaler(field.Type()) {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setFormValue(slice.Index(i), v); err != nil {
+				return v, err
+			}
+		}
+		field.Set(slice)
+		return "", nil
+	}
+	return values[0], setFormValue(field, values[0])
+}
+
+func setFormValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFormValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	if field.Type() == durationType {
+		if value == "" {
+			field.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value == "" {
+			value = "0"
+		}
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value == "" {
+			value = "0"
+		}
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if value == "" {
+			value = "0"
+		}
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
 	return nil
 }
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/misc/logctx"
+)
+
+// LoggerContextKey stores the request *slog.Logger in the Echo context.
+const LoggerContextKey = "logger"
+
+type ContextLoggerOptions struct {
+	// Logger is the parent of every request logger, default slog.Default().
+	Logger *slog.Logger
+}
+
+// ContextLoggerMiddleware derives a per-request logger carrying trace_id and request_id and stores it
+// in the Echo context and the request context (logctx.LoggerFrom). JWTMiddleware and TGMiddleware
+// add user_id to it once the user is known. It must run after TraceMiddleware.
+func ContextLoggerMiddleware(opts ContextLoggerOptions) echo.MiddlewareFunc {
+	base := opts.Logger
+	if base == nil {
+		base = slog.Default()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var attrs []any
+			traceID, _ := c.Get("traceId").(string)
+			if traceID != "" {
+				attrs = append(attrs, slog.String("trace_id", traceID))
+			}
+			if requestID, _ := c.Get("requestId").(string); requestID != "" && requestID != traceID {
+				attrs = append(attrs, slog.String("request_id", requestID))
+			}
+			setLogger(c, base.With(attrs...))
+			return next(c)
+		}
+	}
+}
+
+// Logger returns the request logger, or logctx.LoggerFrom(request context) when none was set.
+func Logger(c echo.Context) *slog.Logger {
+	if l, ok := c.Get(LoggerContextKey).(*slog.Logger); ok {
+		return l
+	}
+	return logctx.LoggerFrom(c.Request().Context())
+}
+
+func setLogger(c echo.Context, l *slog.Logger) {
+	c.Set(LoggerContextKey, l)
+	c.SetRequest(c.Request().WithContext(logctx.WithLogger(c.Request().Context(), l)))
+}
+
+// addUserToLogger adds user attributes to the request logger if ContextLoggerMiddleware created one.
+func addUserToLogger(c echo.Context, userID interface{}, userName string) {
+	l, ok := c.Get(LoggerContextKey).(*slog.Logger)
+	if !ok {
+		return
+	}
+	attrs := []any{slog.String("user_id", fmt.Sprint(userID))}
+	if userName != "" {
+		attrs = append(attrs, slog.String("user_name", userName))
+	}
+	setLogger(c, l.With(attrs...))
+}
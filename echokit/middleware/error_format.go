@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+)
+
+// ErrorFormatMiddleware selects how error responses are rendered for the wrapped routes.
+func ErrorFormatMiddleware(format schemas.ErrorFormat) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(schemas.ErrorFormatContextKey, format)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+)
+
+const pgUniqueViolation = "23505"
+
+// HTTPErrorHandler is an echo.HTTPErrorHandler that renders every error as schemas.ApiError
+// (or schemas.ValidationError for validator errors). 5xx errors are logged with the request logger.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, payload := resolveError(c, err)
+
+	if status >= http.StatusInternalServerError {
+		Logger(c).LogAttrs(c.Request().Context(), slog.LevelError, "REQUEST_FAILED",
+			slog.Int("status", status),
+			slog.String("path", c.Request().URL.Path),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = schemas.WriteError(c, status, payload)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// ResponseStatus returns the status the request ends with: the written one, or the one
+// HTTPErrorHandler will write for err when the handler has not responded yet.
+func ResponseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	status, _ := resolveError(c, err)
+	return status
+}
+
+// resolveError maps known error types to an HTTP status and response payload.
+func resolveError(c echo.Context, err error) (int, interface{}) {
+	var (
+		appErr           *schemas.AppError
+		httpErr          *echo.HTTPError
+		validationErrors validator.ValidationErrors
+		pgErr            *pgconn.PgError
+	)
+
+	switch {
+	case errors.As(err, &appErr):
+		return appErr.Response(c)
+	case errors.As(err, &validationErrors):
+		return buildValidationResponse(c, http.StatusUnprocessableEntity, schemas.VALIDATION_FAILED, "Validation failed", validationErrors)
+	case errors.As(err, &httpErr):
+		message := http.StatusText(httpErr.Code)
+		if httpErr.Message != nil {
+			message = fmt.Sprint(httpErr.Message)
+		}
+		return httpErr.Code, schemas.GenError(c, schemas.ErrorCodeForStatus(httpErr.Code), message, nil)
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, schemas.GenError(c, schemas.TIMEOUT, "Request timed out", nil)
+	case errors.Is(err, pgx.ErrNoRows), errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound, schemas.GenNotFoundError(c)
+	case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		return http.StatusConflict, schemas.GenError(c, schemas.CONFLICT, "Resource already exists", nil)
+	}
+
+	return http.StatusInternalServerError, schemas.GenInternalServerError(c)
+}
@@ -1,26 +1,40 @@
 package middleware
 
 import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-func PathUuidV4Middleware(param string) echo.MiddlewareFunc {
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			uuid := c.Param(param)
-			if !isValidUuidV4(uuid) {
-				return echo.NewHTTPError(400, "Invalid UUIDv4 in path parameter")
-			}
-			return next(c)
-		}
-	}
-}
+// pathUUIDValidator backs PathUuidV4Middleware independently of the Echo validator. Its "uuid4_any" tag
+// accepts what uuid.Parse accepts with version 4: any letter case and any variant, unlike "uuid4".
+var pathUUIDValidator = func() *CustomValidator {
+	v := validator.New()
+	v.RegisterValidation("uuid4_any", func(fl validator.FieldLevel) bool {
+		u, err := uuid.Parse(fl.Field().String())
+		return err == nil && u.Version() == uuid.Version(4)
+	})
+	return &CustomValidator{Validator: v}
+}()
 
-func isValidUuidV4(s string) bool {
-	u, err := uuid.Parse(s)
-	if err != nil {
-		return false
-	}
-	return u.Version() == uuid.Version(4)
+// PathUuidV4Middleware validates that a path parameter is a UUIDv4.
+// It is ValidationMiddleware over path params with a one-field schema; the value is stored under "validatedPath".
+func PathUuidV4Middleware(param string) echo.MiddlewareFunc {
+	schemaType := reflect.StructOf([]reflect.StructField{{
+		Name: "ID",
+		Type: reflect.TypeOf(""),
+		Tag:  reflect.StructTag(fmt.Sprintf(`param:%q validate:"required,uuid4_any"`, param)),
+	}})
+	return ValidationMiddleware(func() interface{} {
+		return reflect.New(schemaType).Interface()
+	}, ValidationOptions{
+		Source:        ValidationSourcePath,
+		ContextKey:    "validatedPath",
+		FailureStatus: http.StatusBadRequest,
+		Validator:     pathUUIDValidator,
+	})
 }
@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	echoMw "github.com/labstack/echo/v4/middleware"
@@ -59,26 +63,125 @@ func RequestLogger(l *gologger.Logger) echo.MiddlewareFunc {
 				tracePart = fmt.Sprintf("(%s%s%s)", traceColor, traceId, reset)
 			}
 
-			// Add req id if exists
+			// Add req id if it differs from the trace id
+			requestID, _ := c.Get("requestId").(string)
 			reqIDPart := ""
-			if v.RequestID != "" {
-				reqIDPart = fmt.Sprintf(" request_id=%s", v.RequestID)
+			if requestID != "" && requestID != traceId {
+				reqIDPart = fmt.Sprintf(" request_id=%s", requestID)
 			}
 
 			// Build final message
 			msg := fmt.Sprintf("%s %s %s %s %s%s",
 				methodField, statusField, pathField, latencyField, tracePart, reqIDPart)
 
+			if exchange, ok := c.Get(BodyCaptureContextKey).(*CapturedExchange); ok {
+				if data, err := json.Marshal(exchange); err == nil {
+					msg = fmt.Sprintf("%s capture=%s", msg, data)
+				}
+			}
+
 			// Log message
 			httpType := gologger.LogType("HTTP")
 			if v.Error == nil {
-				l.Log(gologger.LevelInfo, httpType, msg, v.RequestID)
+				l.Log(gologger.LevelInfo, httpType, msg, traceId)
 			} else {
 				full := fmt.Sprintf("%s error=%s", msg, v.Error.Error())
-				l.Log(gologger.LevelError, httpType, full, v.RequestID)
+				l.Log(gologger.LevelError, httpType, full, traceId)
+			}
+
+			return nil
+		},
+	})
+}
+
+type RequestLoggerOptions struct {
+	// Handler receives the records, default slog.Default().Handler().
+	// Use slog.NewJSONHandler for log pipelines such as Loki.
+	Handler slog.Handler
+	// Skipper excludes requests, e.g. health checks.
+	Skipper func(c echo.Context) bool
+	// SuccessSampleRate logs only this share (0..1) of requests with status below 400.
+	// 0 logs all of them. Errors and slow requests are always logged.
+	SuccessSampleRate float64
+	// SlowThreshold logs requests taking longer at Warn level with slow=true, 0 disables it.
+	SlowThreshold time.Duration
+}
+
+// StructuredRequestLogger logs one "HTTP_REQUEST" record per request with key/value fields
+// (method, route, status, latency_ms, bytes, remote_ip, trace_id, user_id, ...) instead of
+// the coloured line of RequestLogger.
+func StructuredRequestLogger(opts RequestLoggerOptions) echo.MiddlewareFunc {
+	handler := opts.Handler
+	if handler == nil {
+		handler = slog.Default().Handler()
+	}
+	logger := slog.New(handler)
+
+	return echoMw.RequestLoggerWithConfig(echoMw.RequestLoggerConfig{
+		Skipper: func(c echo.Context) bool {
+			return opts.Skipper != nil && opts.Skipper(c)
+		},
+		LogLatency:      true,
+		LogRemoteIP:     true,
+		LogMethod:       true,
+		LogURI:          true,
+		LogRoutePath:    true,
+		LogUserAgent:    true,
+		LogStatus:       true,
+		LogResponseSize: true,
+		LogError:        true,
+		HandleError:     true,
+		LogValuesFunc: func(c echo.Context, v echoMw.RequestLoggerValues) error {
+			slow := opts.SlowThreshold > 0 && v.Latency >= opts.SlowThreshold
+
+			level := slog.LevelInfo
+			switch {
+			case v.Status >= 500:
+				level = slog.LevelError
+			case v.Status >= 400, slow:
+				level = slog.LevelWarn
+			case opts.SuccessSampleRate > 0 && opts.SuccessSampleRate < 1 && rand.Float64() >= opts.SuccessSampleRate:
+				return nil
 			}
 
+			ctx := c.Request().Context()
+			if !logger.Enabled(ctx, level) {
+				return nil
+			}
+			logger.LogAttrs(ctx, level, "HTTP_REQUEST", requestLogAttrs(c, v, slow)...)
 			return nil
 		},
 	})
 }
+
+func requestLogAttrs(c echo.Context, v echoMw.RequestLoggerValues, slow bool) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", v.Method),
+		slog.String("route", v.RoutePath),
+		slog.String("uri", v.URI),
+		slog.Int("status", v.Status),
+		slog.Float64("latency_ms", float64(v.Latency.Microseconds())/1000),
+		slog.Int64("bytes", v.ResponseSize),
+		slog.String("remote_ip", v.RemoteIP),
+		slog.String("user_agent", v.UserAgent),
+	}
+	if traceID, _ := c.Get("traceId").(string); traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", traceID))
+	}
+	if requestID, _ := c.Get("requestId").(string); requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if userID := c.Get("userID"); userID != nil {
+		attrs = append(attrs, slog.String("user_id", fmt.Sprint(userID)))
+	}
+	if slow {
+		attrs = append(attrs, slog.Bool("slow", true))
+	}
+	if exchange, ok := c.Get(BodyCaptureContextKey).(*CapturedExchange); ok {
+		attrs = append(attrs, slog.Any("capture", exchange))
+	}
+	if v.Error != nil {
+		attrs = append(attrs, slog.String("error", v.Error.Error()))
+	}
+	return attrs
+}
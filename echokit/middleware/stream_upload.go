@@ -0,0 +1,254 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+	"github.com/nrf24l01/go-web-utils/misc/logctx"
+)
+
+// sniffLen is how many leading bytes of a file are used to detect its type (mimetype's default limit).
+const sniffLen = 3072
+
+var errFileTooLarge = errors.New("file too large")
+
+// FileSink receives a streamed file. r fails with an error when a limit is exceeded mid-stream;
+// Store must then abort and return that error. The returned location is reported in StreamedFile.
+type FileSink interface {
+	Store(ctx context.Context, fileName, contentType string, r io.Reader) (location string, err error)
+}
+
+// FileRemover is optionally implemented by sinks to roll back stored files when a later part fails.
+type FileRemover interface {
+	Remove(ctx context.Context, location string) error
+}
+
+// FileLimits restricts files of one form field.
+type FileLimits struct {
+	MaxSize      int64    // bytes, 0 = unlimited
+	AllowedTypes []string // detected MIME types, empty = any
+}
+
+type StreamUploadOptions struct {
+	Sink FileSink
+	// Limits applies to every file field without an entry in FieldLimits.
+	Limits      FileLimits
+	FieldLimits map[string]FileLimits
+	// MaxFiles limits the number of files, 0 = unlimited.
+	MaxFiles int
+	// MaxValueSize limits each non-file value, default 1MB.
+	MaxValueSize int64
+	// ContextKey stores the *StreamedUpload, default "streamedUpload".
+	ContextKey string
+	// Timeout replaces the server read and write deadlines (WebAppConfig.ReadTimeout and WriteTimeout)
+	// for the request, counted from when the middleware runs. 0 removes them, so only the client
+	// connection bounds the upload.
+	Timeout time.Duration
+}
+
+// StreamedFile describes a file that has been written to the sink.
+type StreamedFile struct {
+	Field       string
+	FileName    string
+	ContentType string // detected from content, not taken from the client
+	Size        int64
+	SHA256      string
+	Location    string
+}
+
+type StreamedUpload struct {
+	Files  []StreamedFile
+	Values url.Values
+}
+
+// StreamUploadMiddleware reads multipart/form-data part by part and pipes every file straight to
+// opts.Sink without buffering it to memory or temp files. File type is detected from the first bytes
+// and size limits are enforced while streaming; the SHA-256 of each file is computed on the way.
+//
+// A global body limit (WebAppConfig.BodyLimit in server.New, 10M by default) still applies to the whole
+// request and cuts large uploads off: exempt upload routes with server.Options.BodyLimitSkipper or raise it.
+// The server read and write timeouts would abort them as well, so the middleware replaces them with
+// opts.Timeout for its requests.
+func StreamUploadMiddleware(opts StreamUploadOptions) echo.MiddlewareFunc {
+	if opts.MaxValueSize <= 0 {
+		opts.MaxValueSize = 1 << 20
+	}
+	if opts.ContextKey == "" {
+		opts.ContextKey = "streamedUpload"
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			var deadline time.Time
+			if opts.Timeout > 0 {
+				deadline = time.Now().Add(opts.Timeout)
+			}
+			rc := http.NewResponseController(c.Response().Writer)
+			if err := errors.Join(rc.SetReadDeadline(deadline), rc.SetWriteDeadline(deadline)); err != nil {
+				logctx.LoggerFrom(ctx).LogAttrs(ctx, slog.LevelWarn, "UPLOAD_DEADLINE_FAILED",
+					slog.String("error", err.Error()),
+				)
+			}
+
+			reader, err := c.Request().MultipartReader()
+			if err != nil {
+				status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid multipart form", err)
+				return schemas.WriteError(c, status, payload)
+			}
+
+			upload := &StreamedUpload{Values: url.Values{}}
+			rollback := func() {
+				remover, ok := opts.Sink.(FileRemover)
+				if !ok {
+					return
+				}
+				for _, f := range upload.Files {
+					if err := remover.Remove(context.WithoutCancel(ctx), f.Location); err != nil {
+						logctx.LoggerFrom(ctx).LogAttrs(ctx, slog.LevelWarn, "UPLOAD_ROLLBACK_FAILED",
+							slog.String("location", f.Location),
+							slog.String("error", err.Error()),
+						)
+					}
+				}
+			}
+
+			for {
+				part, err := reader.NextPart()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					rollback()
+					status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid multipart form", err)
+					return schemas.WriteError(c, status, payload)
+				}
+
+				field := part.FormName()
+				if part.FileName() == "" {
+					value, err := io.ReadAll(io.LimitReader(part, opts.MaxValueSize+1))
+					part.Close()
+					if err != nil || int64(len(value)) > opts.MaxValueSize {
+						rollback()
+						return streamFieldError(c, field, "max", strconv.FormatInt(opts.MaxValueSize, 10), reflect.String)
+					}
+					upload.Values.Add(field, string(value))
+					continue
+				}
+
+				if opts.MaxFiles > 0 && len(upload.Files) >= opts.MaxFiles {
+					part.Close()
+					rollback()
+					return streamFieldError(c, field, "max", strconv.Itoa(opts.MaxFiles), reflect.Slice)
+				}
+
+				limits, ok := opts.FieldLimits[field]
+				if !ok {
+					limits = opts.Limits
+				}
+				file, tag, param, err := streamFile(ctx, opts.Sink, part, limits)
+				part.Close()
+				if tag != "" {
+					rollback()
+					return streamFieldError(c, field, tag, param, reflect.Invalid)
+				}
+				if err != nil {
+					rollback()
+					return err
+				}
+				upload.Files = append(upload.Files, file)
+			}
+
+			c.Set(opts.ContextKey, upload)
+			return next(c)
+		}
+	}
+}
+
+// streamFile sniffs, limits, hashes and stores one file part. A non-empty tag means a limit
+// was violated ("filetype" or "filesize") and param describes that limit.
+func streamFile(ctx context.Context, sink FileSink, part *multipart.Part, limits FileLimits) (StreamedFile, string, string, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return StreamedFile{}, "", "", err
+	}
+	head = head[:n]
+
+	mtype := mimetype.Detect(head)
+	if len(limits.AllowedTypes) > 0 && !mimeAllowed(mtype, limits.AllowedTypes) {
+		return StreamedFile{}, "filetype", strings.Join(limits.AllowedTypes, ";"), nil
+	}
+
+	hasher := sha256.New()
+	counter := &limitedCounter{r: io.MultiReader(bytes.NewReader(head), part), max: limits.MaxSize}
+	location, err := sink.Store(ctx, part.FileName(), mtype.String(), io.TeeReader(counter, hasher))
+	if errors.Is(err, errFileTooLarge) || counter.exceeded {
+		return StreamedFile{}, "filesize", strconv.FormatInt(limits.MaxSize, 10) + "B", nil
+	}
+	if err != nil {
+		return StreamedFile{}, "", "", err
+	}
+
+	return StreamedFile{
+		Field:       part.FormName(),
+		FileName:    part.FileName(),
+		ContentType: mtype.String(),
+		Size:        counter.n,
+		SHA256:      hex.EncodeToString(hasher.Sum(nil)),
+		Location:    location,
+	}, "", "", nil
+}
+
+func mimeAllowed(mtype *mimetype.MIME, allowed []string) bool {
+	for _, a := range allowed {
+		if mtype.Is(a) {
+			return true
+		}
+	}
+	return false
+}
+
+func streamFieldError(c echo.Context, field, tag, param string, kind reflect.Kind) error {
+	apiErr := schemas.GenError(c, schemas.VALIDATION_FAILED, "Validation failed", nil)
+	return schemas.WriteError(c, http.StatusUnprocessableEntity, schemas.ValidationError{
+		ApiError: apiErr,
+		FieldErrors: []schemas.FieldError{{
+			Field: field,
+			Issue: translate(RequestLocale(c), field, tag, param, kind),
+		}},
+	})
+}
+
+// limitedCounter counts bytes read and fails with errFileTooLarge once max is exceeded.
+type limitedCounter struct {
+	r        io.Reader
+	max      int64
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedCounter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.max > 0 && l.n > l.max {
+		l.exceeded = true
+		return 0, errFileTooLarge
+	}
+	return n, err
+}
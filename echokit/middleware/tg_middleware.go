@@ -17,7 +17,7 @@ func TGMiddleware(config config.TgWebAppConfig) echo.MiddlewareFunc {
 			authHeader := c.Request().Header.Get("Authorization")
 
 			if authHeader == "" {
-				return c.JSON(http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "missing token", nil))
+				return schemas.WriteError(c, http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "missing token", nil))
 			}
 
 			// Убираем "Bearer " из заголовка
@@ -25,24 +25,25 @@ func TGMiddleware(config config.TgWebAppConfig) echo.MiddlewareFunc {
 			if len(authHeader) > 4 && authHeader[:4] == "tma " {
 				tokenString = authHeader[4:]
 			} else {
-				return c.JSON(http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "invalid token format", nil))
+				return schemas.WriteError(c, http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "invalid token format", nil))
 			}
 
 			expInHour := time.Duration(config.InitDataExpireHours) * time.Hour
 
 			verifyErr := initdata.Validate(tokenString, config.TgBotToken, expInHour)
 			if verifyErr != nil {
-				return c.JSON(http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "invalid token", nil))
+				return schemas.WriteError(c, http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "invalid token", nil))
 			}
 			tokenData, err := initdata.Parse(tokenString)
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "invalid token", nil))
+				return schemas.WriteError(c, http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "invalid token", nil))
 			}
 			if tokenData.User.ID != 0 {
 				c.Set("userID", tokenData.User.ID)
 				c.Set("userName", tokenData.User.Username)
+				addUserToLogger(c, tokenData.User.ID, tokenData.User.Username)
 			} else {
-				return c.JSON(http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "token does not contain user data", nil))
+				return schemas.WriteError(c, http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "token does not contain user data", nil))
 			}
 			return next(c)
 		}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
 	"log/slog"
 	"net/http"
 	"time"
@@ -9,21 +10,157 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/nrf24l01/go-web-utils/echokit/middleware"
+
+// maxRequestIDLen bounds client supplied request IDs so they are safe to log and echo back.
+const maxRequestIDLen = 128
+
+type traceIDContextKey struct{}
+
+type TraceOptions struct {
+	// TracerProvider starts server spans, default otel.GetTracerProvider().
+	TracerProvider trace.TracerProvider
+	// Propagator reads the parent context from headers, default W3C traceparent/tracestate and baggage.
+	Propagator propagation.TextMapPropagator
+	// RequestIDHeader is read from the request and echoed back, default "X-Request-ID".
+	RequestIDHeader string
+	// TraceIDHeader carries the trace ID in the response, default "X-Trace-Id".
+	TraceIDHeader string
+}
+
 func TraceMiddleware() echo.MiddlewareFunc {
+	return TraceMiddlewareWithOptions(TraceOptions{})
+}
+
+// TraceMiddlewareWithOptions continues the trace from an incoming traceparent (or starts a new one)
+// and wraps the request in a server span. The trace ID is stored as "traceId" in the Echo context,
+// in the request context (see TraceIDFromContext) and in the response headers.
+// Without a configured OpenTelemetry SDK the trace ID is still generated and propagated.
+func TraceMiddlewareWithOptions(opts TraceOptions) echo.MiddlewareFunc {
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+	if opts.Propagator == nil {
+		opts.Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = echo.HeaderXRequestID
+	}
+	if opts.TraceIDHeader == "" {
+		opts.TraceIDHeader = "X-Trace-Id"
+	}
+	tracer := opts.TracerProvider.Tracer(tracerName)
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			uuid, err := uuid.NewV7()
-			if err != nil {
-				slog.LogAttrs(context.Background(), slog.LevelError, "REQUEST_ID_GENERATION_FAILED",
-					slog.String("error", err.Error()),
-				)
-				return c.JSON(http.StatusServiceUnavailable, schemas.GenError(c, schemas.INTERNAL_SERVER_ERROR, "REQUEST_ID_GENERATION_FAILED", nil))
+			req := c.Request()
+			ctx := opts.Propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			if route == "" {
+				route = req.URL.Path
 			}
-			c.Set("traceId", uuid.String())
+			ctx, span := tracer.Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(c.Path()),
+					semconv.URLPath(req.URL.Path),
+					semconv.URLScheme(c.Scheme()),
+					semconv.ServerAddress(req.Host),
+					semconv.ClientAddress(c.RealIP()),
+					semconv.UserAgentOriginal(req.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			sc := span.SpanContext()
+			if !sc.IsValid() {
+				// no SDK and no parent: the span is a no-op, so mint the IDs ourselves
+				generated, err := newSpanContext()
+				if err != nil {
+					slog.LogAttrs(context.Background(), slog.LevelError, "REQUEST_ID_GENERATION_FAILED",
+						slog.String("error", err.Error()),
+					)
+					return schemas.WriteError(c, http.StatusServiceUnavailable, schemas.GenError(c, schemas.INTERNAL_SERVER_ERROR, "REQUEST_ID_GENERATION_FAILED", nil))
+				}
+				sc = generated
+				ctx = trace.ContextWithSpanContext(ctx, sc)
+			}
+			traceID := sc.TraceID().String()
+
+			requestID := req.Header.Get(opts.RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = traceID
+			}
+
+			ctx = context.WithValue(ctx, traceIDContextKey{}, traceID)
+			c.SetRequest(req.WithContext(ctx))
+			c.Set("traceId", traceID)
+			c.Set("requestId", requestID)
 			c.Set("timestamp", time.Now().UTC().Format(time.RFC3339))
-			return next(c)
+
+			header := c.Response().Header()
+			header.Set(opts.TraceIDHeader, traceID)
+			header.Set(opts.RequestIDHeader, requestID)
+
+			err := next(c)
+
+			status := ResponseStatus(c, err)
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if err != nil {
+				span.RecordError(err)
+			}
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return err
+		}
+	}
+}
+
+// TraceIDFromContext returns the trace ID of the request (or of any span) carried by ctx.
+func TraceIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(traceIDContextKey{}).(string); ok {
+		return id
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// newSpanContext builds a span context whose trace ID is a UUIDv7, so IDs stay time-ordered.
+func newSpanContext() (trace.SpanContext, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return trace.SpanContext{}, err
+	}
+	var spanID trace.SpanID
+	if _, err := rand.Read(spanID[:]); err != nil {
+		return trace.SpanContext{}, err
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID(id),
+		SpanID:  spanID,
+	}), nil
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
 		}
 	}
+	return true
 }
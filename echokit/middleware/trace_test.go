@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID  = "00f067aa0ba902b7"
+	traceparent   = "00-" + parentTraceID + "-" + parentSpanID + "-01"
+)
+
+// seen is what the handler observed about the request trace.
+type seen struct {
+	traceID     string
+	ctxTraceID  string
+	requestID   string
+	spanContext trace.SpanContext
+}
+
+func serveTraced(t *testing.T, opts TraceOptions, status int, headers map[string]string) (*httptest.ResponseRecorder, seen) {
+	t.Helper()
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	var s seen
+	e.GET("/items/:id", func(c echo.Context) error {
+		s.traceID, _ = c.Get("traceId").(string)
+		s.requestID, _ = c.Get("requestId").(string)
+		s.ctxTraceID = TraceIDFromContext(c.Request().Context())
+		s.spanContext = trace.SpanContextFromContext(c.Request().Context())
+		if status >= http.StatusInternalServerError {
+			return echo.NewHTTPError(status)
+		}
+		return c.NoContent(status)
+	}, TraceMiddlewareWithOptions(opts))
+
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec, s
+}
+
+func newRecordingProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func spanAttr(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTraceMiddlewareContinuesPropagatedTrace(t *testing.T) {
+	tp, exporter := newRecordingProvider()
+	rec, s := serveTraced(t, TraceOptions{TracerProvider: tp}, http.StatusOK, map[string]string{
+		"traceparent": traceparent,
+		"tracestate":  "vendor=value",
+	})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+
+	if got := span.SpanContext.TraceID().String(); got != parentTraceID {
+		t.Errorf("span trace ID = %s, want %s", got, parentTraceID)
+	}
+	if got := span.Parent.SpanID().String(); got != parentSpanID {
+		t.Errorf("span parent ID = %s, want %s", got, parentSpanID)
+	}
+	if !span.Parent.IsRemote() {
+		t.Error("span parent is not remote")
+	}
+	if span.SpanContext.SpanID().String() == parentSpanID {
+		t.Error("middleware reused the parent span instead of starting a new one")
+	}
+	if got := span.SpanContext.TraceState().Get("vendor"); got != "value" {
+		t.Errorf("tracestate vendor = %q, want %q", got, "value")
+	}
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span kind = %v, want server", span.SpanKind)
+	}
+	if span.Name != "GET /items/:id" {
+		t.Errorf("span name = %q, want %q", span.Name, "GET /items/:id")
+	}
+
+	if s.traceID != parentTraceID || s.ctxTraceID != parentTraceID {
+		t.Errorf("handler saw traceId %q and context trace ID %q, want %s", s.traceID, s.ctxTraceID, parentTraceID)
+	}
+	if s.spanContext.SpanID() != span.SpanContext.SpanID() {
+		t.Error("request context does not carry the server span")
+	}
+	if got := rec.Header().Get("X-Trace-Id"); got != parentTraceID {
+		t.Errorf("X-Trace-Id = %q, want %s", got, parentTraceID)
+	}
+}
+
+func TestTraceMiddlewareStartsRootSpan(t *testing.T) {
+	tp, exporter := newRecordingProvider()
+	rec, s := serveTraced(t, TraceOptions{TracerProvider: tp}, http.StatusOK, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Parent.IsValid() {
+		t.Errorf("root span has parent %v", span.Parent)
+	}
+	traceID := span.SpanContext.TraceID().String()
+	if s.traceID != traceID || rec.Header().Get("X-Trace-Id") != traceID {
+		t.Errorf("traceId %q / header %q do not match span trace ID %s", s.traceID, rec.Header().Get("X-Trace-Id"), traceID)
+	}
+	if got := rec.Header().Get(echo.HeaderXRequestID); got != traceID {
+		t.Errorf("X-Request-ID = %q, want trace ID %s", got, traceID)
+	}
+}
+
+func TestTraceMiddlewareWithoutSDK(t *testing.T) {
+	opts := TraceOptions{TracerProvider: noop.NewTracerProvider()}
+
+	t.Run("generates UUIDv7 trace ID", func(t *testing.T) {
+		rec, s := serveTraced(t, opts, http.StatusOK, nil)
+
+		id, err := trace.TraceIDFromHex(s.traceID)
+		if err != nil {
+			t.Fatalf("traceId %q is not a trace ID: %v", s.traceID, err)
+		}
+		if v := uuid.UUID(id).Version(); v != 7 {
+			t.Errorf("trace ID version = %d, want 7", v)
+		}
+		if s.ctxTraceID != s.traceID || s.spanContext.TraceID() != id {
+			t.Errorf("request context trace ID %q does not match %q", s.ctxTraceID, s.traceID)
+		}
+		if got := rec.Header().Get("X-Trace-Id"); got != s.traceID {
+			t.Errorf("X-Trace-Id = %q, want %s", got, s.traceID)
+		}
+	})
+
+	t.Run("keeps propagated trace ID", func(t *testing.T) {
+		rec, s := serveTraced(t, opts, http.StatusOK, map[string]string{"traceparent": traceparent})
+
+		if s.traceID != parentTraceID || s.ctxTraceID != parentTraceID {
+			t.Errorf("handler saw traceId %q and context trace ID %q, want %s", s.traceID, s.ctxTraceID, parentTraceID)
+		}
+		if got := rec.Header().Get("X-Trace-Id"); got != parentTraceID {
+			t.Errorf("X-Trace-Id = %q, want %s", got, parentTraceID)
+		}
+	})
+}
+
+func TestTraceMiddlewareRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		passThru bool
+	}{
+		{name: "valid", header: echo.HeaderXRequestID, value: "req-123", passThru: true},
+		{name: "custom header", header: "X-Correlation-Id", value: "corr-1", passThru: true},
+		{name: "contains space", header: echo.HeaderXRequestID, value: "req 123"},
+		{name: "too long", header: echo.HeaderXRequestID, value: strings.Repeat("a", maxRequestIDLen+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := TraceOptions{TracerProvider: noop.NewTracerProvider(), RequestIDHeader: tt.header}
+			rec, s := serveTraced(t, opts, http.StatusOK, map[string]string{tt.header: tt.value})
+
+			want := s.traceID
+			if tt.passThru {
+				want = tt.value
+			}
+			if s.requestID != want {
+				t.Errorf("requestId = %q, want %q", s.requestID, want)
+			}
+			if got := rec.Header().Get(tt.header); got != want {
+				t.Errorf("%s response header = %q, want %q", tt.header, got, want)
+			}
+		})
+	}
+}
+
+func TestTraceMiddlewareRecordsStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantError bool
+	}{
+		{status: http.StatusNoContent},
+		{status: http.StatusBadGateway, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			tp, exporter := newRecordingProvider()
+			rec, _ := serveTraced(t, TraceOptions{TracerProvider: tp}, tt.status, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("response status = %d, want %d", rec.Code, tt.status)
+			}
+
+			span := exporter.GetSpans()[0]
+			if v, ok := spanAttr(span, "http.response.status_code"); !ok || v.AsInt64() != int64(tt.status) {
+				t.Errorf("http.response.status_code = %v, want %d", v.AsInterface(), tt.status)
+			}
+			if got := span.Status.Code == codes.Error; got != tt.wantError {
+				t.Errorf("span status = %v, want error %v", span.Status.Code, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestTraceIDFromContext(t *testing.T) {
+	id, _ := trace.TraceIDFromHex(parentTraceID)
+	spanID, _ := trace.SpanIDFromHex(parentSpanID)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: id, SpanID: spanID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "empty", ctx: context.Background(), want: ""},
+		{name: "span context", ctx: trace.ContextWithSpanContext(context.Background(), sc), want: parentTraceID},
+		{name: "stored ID wins", ctx: context.WithValue(trace.ContextWithSpanContext(context.Background(), sc), traceIDContextKey{}, "stored"), want: "stored"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TraceIDFromContext(tt.ctx); got != tt.want {
+				t.Errorf("TraceIDFromContext = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LocaleContextKey overrides Accept-Language negotiation when set on the echo context (e.g. from a user profile).
+const LocaleContextKey = "locale"
+
+// DefaultLocale is used when the request does not ask for any registered locale.
+var DefaultLocale = "en"
+
+// Message templates support the {field}, {param} and {tag} placeholders.
+// A tag may have kind-specific variants: "<tag>.string", "<tag>.items" (slices, arrays, maps)
+// and "<tag>.number"; the plain "<tag>" is used when no variant matches.
+// "default" is used for tags without any message.
+var (
+	messagesMu sync.RWMutex
+	messages   = map[string]map[string]string{
+		"en": {
+			"default":    "{field} failed validation for {tag}",
+			"required":   "{field} is required",
+			"email":      "{field} must be a valid email address",
+			"url":        "{field} must be a valid URL",
+			"uuid":       "{field} must be a valid UUID",
+			"uuid4":      "{field} must be a valid UUIDv4",
+			"uuid4_any":  "{field} must be a valid UUIDv4",
+			"numeric":    "{field} must be numeric",
+			"oneof":      "{field} must be one of [{param}]",
+			"min.string": "{field} must be at least {param} characters",
+			"min.items":  "{field} must contain at least {param} items",
+			"min.number": "{field} must be at least {param}",
+			"max.string": "{field} must not exceed {param} characters",
+			"max.items":  "{field} must contain at most {param} items",
+			"max.number": "{field} must not exceed {param}",
+			"len.string": "{field} must be exactly {param} characters",
+			"len.items":  "{field} must contain exactly {param} items",
+			"len.number": "{field} must be equal to {param}",
+			"gt":         "{field} must be greater than {param}",
+			"gte":        "{field} must be greater than or equal to {param}",
+			"lt":         "{field} must be less than {param}",
+			"lte":        "{field} must be less than or equal to {param}",
+			"regex":      "{field} has invalid format",
+			"filetype":   "{field} must be a file of type {param}",
+			"filesize":   "{field} must not exceed {param}",
+			"conversion": "{field} has invalid value",
+		},
+		"ru": {
+			"default":    "{field} не прошло проверку {tag}",
+			"required":   "{field}: обязательное поле",
+			"email":      "{field}: некорректный email",
+			"url":        "{field}: некорректный URL",
+			"uuid":       "{field}: некорректный UUID",
+			"uuid4":      "{field}: некорректный UUIDv4",
+			"uuid4_any":  "{field}: некорректный UUIDv4",
+			"numeric":    "{field}: должно быть числом",
+			"oneof":      "{field}: допустимые значения [{param}]",
+			"min.string": "{field}: минимальная длина {param} симв.",
+			"min.items":  "{field}: минимум {param} эл.",
+			"min.number": "{field}: значение должно быть не меньше {param}",
+			"max.string": "{field}: максимальная длина {param} симв.",
+			"max.items":  "{field}: максимум {param} эл.",
+			"max.number": "{field}: значение должно быть не больше {param}",
+			"len.string": "{field}: длина должна быть ровно {param} симв.",
+			"len.items":  "{field}: должно быть ровно {param} эл.",
+			"len.number": "{field}: значение должно быть равно {param}",
+			"gt":         "{field}: значение должно быть больше {param}",
+			"gte":        "{field}: значение должно быть не меньше {param}",
+			"lt":         "{field}: значение должно быть меньше {param}",
+			"lte":        "{field}: значение должно быть не больше {param}",
+			"regex":      "{field}: неверный формат",
+			"filetype":   "{field}: допустимые типы файла {param}",
+			"filesize":   "{field}: размер файла не должен превышать {param}",
+			"conversion": "{field}: некорректное значение",
+		},
+	}
+)
+
+// RegisterMessage sets the message template for a validation tag in a locale,
+// e.g. RegisterMessage("ru", "sumrate", "сумма долей должна быть равна 1").
+func RegisterMessage(locale, tag, template string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	locale = normalizeLocale(locale)
+	if messages[locale] == nil {
+		messages[locale] = make(map[string]string)
+	}
+	messages[locale][tag] = template
+}
+
+// RegisterMessages sets several tag templates for a locale at once.
+func RegisterMessages(locale string, templates map[string]string) {
+	for tag, template := range templates {
+		RegisterMessage(locale, tag, template)
+	}
+}
+
+// SupportedLocales returns the locales that have at least one message registered.
+func SupportedLocales() []string {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	locales := make([]string, 0, len(messages))
+	for l := range messages {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// RequestLocale returns the locale for the request: LocaleContextKey if set, otherwise Accept-Language negotiation.
+func RequestLocale(c echo.Context) string {
+	if l, ok := c.Get(LocaleContextKey).(string); ok && l != "" {
+		return normalizeLocale(l)
+	}
+	return NegotiateLocale(c.Request().Header.Get("Accept-Language"))
+}
+
+// NegotiateLocale picks the best registered locale for an Accept-Language header value.
+func NegotiateLocale(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var candidates []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		q := 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			for _, p := range strings.Split(part[i+1:], ";") {
+				p = strings.TrimSpace(p)
+				if v, ok := strings.CutPrefix(p, "q="); ok {
+					if f, err := strconv.ParseFloat(v, 64); err == nil {
+						q = f
+					}
+				}
+			}
+			part = strings.TrimSpace(part[:i])
+		}
+		if q > 0 {
+			candidates = append(candidates, weighted{tag: part, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	for _, cand := range candidates {
+		tag := normalizeLocale(cand.tag)
+		if _, ok := messages[tag]; ok {
+			return tag
+		}
+		if base, _, found := strings.Cut(tag, "-"); found {
+			if _, ok := messages[base]; ok {
+				return base
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// kindSuffix groups reflect kinds for kind-specific templates.
+func kindSuffix(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return ""
+}
+
+// translate renders the message for a failed tag in locale, falling back to DefaultLocale and then "en".
+func translate(locale, fieldName, tag, param string, kind reflect.Kind) string {
+	messagesMu.RLock()
+	template := lookupTemplate(locale, tag, kindSuffix(kind))
+	messagesMu.RUnlock()
+
+	return strings.NewReplacer("{field}", fieldName, "{param}", param, "{tag}", tag).Replace(template)
+}
+
+func lookupTemplate(locale, tag, suffix string) string {
+	for _, l := range []string{normalizeLocale(locale), DefaultLocale, "en"} {
+		m := messages[l]
+		if m == nil {
+			continue
+		}
+		if suffix != "" {
+			if t, ok := m[tag+"."+suffix]; ok {
+				return t
+			}
+		}
+		if t, ok := m[tag]; ok {
+			return t
+		}
+		if suffix == "" {
+			// Kind is unknown: use the first kind-specific variant, strings first.
+			for _, s := range []string{"string", "items", "number"} {
+				if t, ok := m[tag+"."+s]; ok {
+					return t
+				}
+			}
+		}
+	}
+	for _, l := range []string{normalizeLocale(locale), DefaultLocale, "en"} {
+		if t, ok := messages[l]["default"]; ok {
+			return t
+		}
+	}
+	return "{field} failed validation for {tag}"
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StatusCoder lets a Handle response choose its HTTP status (200 by default).
+type StatusCoder interface {
+	StatusCode() int
+}
+
+func factoryOf[T any]() func() interface{} {
+	return func() interface{} {
+		return new(T)
+	}
+}
+
+// Body is BodyValidationMiddleware for schema type T; read the value with GetBody[T].
+func Body[T any]() echo.MiddlewareFunc {
+	return BodyValidationMiddleware(factoryOf[T]())
+}
+
+// Query is QueryValidationMiddleware for schema type T; read the value with GetQuery[T].
+func Query[T any]() echo.MiddlewareFunc {
+	return QueryValidationMiddleware(factoryOf[T]())
+}
+
+// Multipart is MultipartValidationMiddleware for schema type T; read the value with GetBody[T].
+func Multipart[T any]() echo.MiddlewareFunc {
+	return MultipartValidationMiddleware(factoryOf[T]())
+}
+
+// Request is RequestValidationMiddleware for schema type T; read the value with GetRequest[T].
+func Request[T any]() echo.MiddlewareFunc {
+	return RequestValidationMiddleware(factoryOf[T]())
+}
+
+// Get returns the validated *T stored under key.
+func Get[T any](c echo.Context, key string) (*T, bool) {
+	v, ok := c.Get(key).(*T)
+	return v, ok
+}
+
+// MustGet is Get that panics when key holds no *T, which means the route misses its middleware.
+func MustGet[T any](c echo.Context, key string) *T {
+	v, ok := Get[T](c, key)
+	if !ok {
+		panic(fmt.Sprintf("middleware: context key %q does not hold %T", key, v))
+	}
+	return v
+}
+
+func GetBody[T any](c echo.Context) (*T, bool) {
+	return Get[T](c, "validatedBody")
+}
+
+func MustBody[T any](c echo.Context) *T {
+	return MustGet[T](c, "validatedBody")
+}
+
+func GetQuery[T any](c echo.Context) (*T, bool) {
+	return Get[T](c, "validatedQuery")
+}
+
+func MustQuery[T any](c echo.Context) *T {
+	return MustGet[T](c, "validatedQuery")
+}
+
+func GetRequest[T any](c echo.Context) (*T, bool) {
+	return Get[T](c, "validatedRequest")
+}
+
+func MustRequest[T any](c echo.Context) *T {
+	return MustGet[T](c, "validatedRequest")
+}
+
+// Handle adapts a typed function into an echo.HandlerFunc. The request is bound from path params,
+// query, headers and body into Req (see ValidationSourceRequest) and validated; errors returned by fn
+// are left to the HTTPErrorHandler. The response is serialised as JSON with status 200, or the status
+// from StatusCoder.
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) echo.HandlerFunc {
+	handler := func(c echo.Context) error {
+		req := MustRequest[Req](c)
+		resp, err := fn(c.Request().Context(), *req)
+		if err != nil {
+			return err
+		}
+		status := http.StatusOK
+		if sc, ok := any(resp).(StatusCoder); ok {
+			status = sc.StatusCode()
+		}
+		if status == http.StatusNoContent {
+			return c.NoContent(status)
+		}
+		return c.JSON(status, resp)
+	}
+	return Request[Req]()(handler)
+}
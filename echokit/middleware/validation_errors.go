@@ -1,7 +1,8 @@
 package middleware
 
 import (
-	"fmt"
+	"errors"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -12,14 +13,33 @@ import (
 
 // FormatValidationErrors converts validator errors into a slice of schemas.FieldError
 func FormatValidationErrors(err error) []schemas.FieldError {
+	return FormatValidationErrorsLocale(err, DefaultLocale)
+}
+
+// FormatValidationErrorsLocale is FormatValidationErrors with messages translated into locale.
+func FormatValidationErrorsLocale(err error, locale string) []schemas.FieldError {
+	return formatFieldErrors(err, locale, resolveFieldPath)
+}
+
+// FormatSchemaValidationErrors reports fields of schema by their wire names taken from the first
+// present tag in tagKeys ("json", "query", "form", ...), e.g. "items[2].price" instead of "Items[2].Price".
+func FormatSchemaValidationErrors(err error, locale string, schema interface{}, tagKeys ...string) []schemas.FieldError {
+	rootType := reflect.TypeOf(schema)
+	return formatFieldErrors(err, locale, func(fe validator.FieldError) string {
+		return wireFieldPath(rootType, fe.StructNamespace(), tagKeys)
+	})
+}
+
+func formatFieldErrors(err error, locale string, fieldPath func(validator.FieldError) string) []schemas.FieldError {
 	fieldErrors := make([]schemas.FieldError, 0)
 
+	var conversionErrors ConversionErrors
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, fieldError := range validationErrors {
-			fieldName := resolveFieldPath(fieldError)
+			fieldName := fieldPath(fieldError)
 			tag := fieldError.Tag()
 
-			message := getReadableErrorMessage(fieldName, tag, fieldError.Param())
+			message := translate(locale, fieldName, tag, fieldError.Param(), fieldError.Kind())
 			fe := schemas.FieldError{
 				Field:         fieldName,
 				Issue:         message,
@@ -27,6 +47,14 @@ func FormatValidationErrors(err error) []schemas.FieldError {
 			}
 			fieldErrors = append(fieldErrors, fe)
 		}
+	} else if errors.As(err, &conversionErrors) {
+		for _, ce := range conversionErrors {
+			fieldErrors = append(fieldErrors, schemas.FieldError{
+				Field:         ce.Field,
+				Issue:         translate(locale, ce.Field, "conversion", "", reflect.Invalid),
+				RejectedValue: ce.Value,
+			})
+		}
 	} else {
 		// Try to parse common validator error string formats, e.g.:
 		// "Key: 'Struct.Field' Error:Field validation for 'Field' failed on the 'required' tag"
@@ -35,7 +63,7 @@ func FormatValidationErrors(err error) []schemas.FieldError {
 		if len(matches) == 3 {
 			fieldName := matches[1]
 			tag := matches[2]
-			message := getReadableErrorMessage(fieldName, tag, "")
+			message := translate(locale, fieldName, tag, "", reflect.Invalid)
 			fe := schemas.FieldError{
 				Field: strings.ToLower(fieldName),
 				Issue: message,
@@ -73,30 +101,67 @@ func resolveFieldPath(fieldError validator.FieldError) string {
 	return strings.Join(parts, ".")
 }
 
-// getReadableErrorMessage returns a human-readable error message for a validation tag
-func getReadableErrorMessage(fieldName, tag, param string) string {
-	messages := map[string]string{
-		"required":      fmt.Sprintf("%s is required", fieldName),
-		"email":         fmt.Sprintf("%s must be a valid email address", fieldName),
-		"min":           fmt.Sprintf("%s must be at least %s characters", fieldName, param),
-		"max":           fmt.Sprintf("%s must not exceed %s characters", fieldName, param),
-		"numeric":       fmt.Sprintf("%s must be numeric", fieldName),
-		"url":           fmt.Sprintf("%s must be a valid URL", fieldName),
-		"len":           fmt.Sprintf("%s must be exactly %s characters", fieldName, param),
-		"gt":            fmt.Sprintf("%s must be greater than %s", fieldName, param),
-		"gte":           fmt.Sprintf("%s must be greater than or equal to %s", fieldName, param),
-		"lt":            fmt.Sprintf("%s must be less than %s", fieldName, param),
-		"lte":           fmt.Sprintf("%s must be less than or equal to %s", fieldName, param),
-		"fromto":        "from must be less than to",
-		"maxperiod":     "period must not exceed 90 days",
-		"maxfuture":     "timestamp must not be more than 5 minutes in the future",
-		"sumrate":       "approvalRate + declineRate must equal 1",
-		"groupbyperiod": "period is too large for selected groupBy",
+// wireFieldPath converts a validator StructNamespace ("Req.Items[2].Price") into a path made of
+// tagKey names ("items[2].price"). Embedded structs without a tag are flattened like encoding/json does.
+func wireFieldPath(rootType reflect.Type, structNamespace string, tagKeys []string) string {
+	segments := strings.Split(structNamespace, ".")
+	if len(segments) > 1 {
+		segments = segments[1:] // drop the root struct name
+	}
+
+	t := rootType
+	parts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		name, index := seg, ""
+		if i := strings.Index(seg, "["); i >= 0 {
+			name, index = seg[:i], seg[i:]
+		}
+
+		wire := name
+		t = derefType(t)
+		if t != nil && t.Kind() == reflect.Struct {
+			if f, ok := t.FieldByName(name); ok {
+				t = f.Type
+				wire = TagFieldName(f, tagKeys...)
+				if f.Anonymous && wire == f.Name && index == "" {
+					continue
+				}
+			} else {
+				t = nil
+			}
+		} else {
+			t = nil
+		}
+
+		for n := strings.Count(index, "["); n > 0 && t != nil; n-- {
+			t = derefType(t)
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				t = nil
+			}
+		}
+		parts = append(parts, wire+index)
 	}
+	return strings.Join(parts, ".")
+}
 
-	if message, exists := messages[tag]; exists {
-		return message
+// TagFieldName returns the name of f under the first of tagKeys that is set (the part before
+// the first comma), or the Go field name when none is set or the tag is "-".
+func TagFieldName(f reflect.StructField, tagKeys ...string) string {
+	for _, key := range tagKeys {
+		name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
 	}
+	return f.Name
+}
 
-	return fmt.Sprintf("%s failed validation for %s", fieldName, tag)
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
 }
@@ -3,7 +3,9 @@ package middleware
 import (
 	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/nrf24l01/go-web-utils/echokit/schemas"
 )
@@ -14,13 +16,25 @@ const (
 	ValidationSourceBody ValidationSource = iota
 	ValidationSourceQuery
 	ValidationSourceMultipart
+	// ValidationSourceRequest binds path params (`param`), query (`query`), headers (`header`)
+	// and the body (`json`/`form`) into one struct.
+	ValidationSourceRequest
+	// ValidationSourcePath binds only path params (`param`), leaving the body unread.
+	ValidationSourcePath
 )
 
 type ValidationOptions struct {
 	Source     ValidationSource
 	ContextKey string
+	// FailureStatus is the status used when validation fails, default 422.
+	FailureStatus int
+	// Validator replaces the Echo instance's validator for this middleware.
+	Validator echo.Validator
 }
 
+// defaultValidator is used when the Echo instance has no Validator registered.
+var defaultValidator = &CustomValidator{Validator: validator.New()}
+
 func ValidationMiddleware(schemaFactory func() interface{}, opts ValidationOptions) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -31,27 +45,44 @@ func ValidationMiddleware(schemaFactory func() interface{}, opts ValidationOptio
 				form, err := c.MultipartForm()
 				if err != nil {
 					status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid multipart form", err)
-					return c.JSON(status, payload)
+					return schemas.WriteError(c, status, payload)
 				}
 				if err := bindMultipartForm(c, schema, form); err != nil {
 					status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid multipart form", err)
-					return c.JSON(status, payload)
+					return schemas.WriteError(c, status, payload)
+				}
+			case ValidationSourceRequest:
+				if err := bindRequest(c, schema); err != nil {
+					status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid request", err)
+					return schemas.WriteError(c, status, payload)
+				}
+			case ValidationSourcePath:
+				if err := (&echo.DefaultBinder{}).BindPathParams(c, schema); err != nil {
+					status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid path parameters", err)
+					return schemas.WriteError(c, status, payload)
 				}
 			case ValidationSourceQuery:
 				if err := c.Bind(schema); err != nil {
 					status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid query parameters", err)
-					return c.JSON(status, payload)
+					return schemas.WriteError(c, status, payload)
 				}
 			default:
 				if err := c.Bind(schema); err != nil {
 					status, payload := buildValidationResponse(c, http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid request payload", err)
-					return c.JSON(status, payload)
+					return schemas.WriteError(c, status, payload)
 				}
 			}
 
-			if err := c.Validate(schema); err != nil {
-				status, payload := buildValidationResponse(c, http.StatusUnprocessableEntity, schemas.VALIDATION_FAILED, "Validation failed", err)
-				return c.JSON(status, payload)
+			if err := validateSchema(c, schema, opts.Validator); err != nil {
+				status := opts.FailureStatus
+				if status == 0 {
+					status = http.StatusUnprocessableEntity
+				}
+				apiErr := schemas.GenError(c, schemas.ErrorCodeForStatus(status), "Validation failed", nil)
+				return schemas.WriteError(c, status, schemas.ValidationError{
+					ApiError:    apiErr,
+					FieldErrors: FormatSchemaValidationErrors(err, RequestLocale(c), schema, tagKeysForSource(c, opts.Source)...),
+				})
 			}
 
 			contextKey := opts.ContextKey
@@ -71,6 +102,63 @@ func buildValidationResponse(c echo.Context, status int, code schemas.ErrorCode,
 	apiErr := schemas.GenError(c, code, message, nil)
 	return status, schemas.ValidationError{
 		ApiError:    apiErr,
-		FieldErrors: FormatValidationErrors(err),
+		FieldErrors: FormatValidationErrorsLocale(err, RequestLocale(c)),
+	}
+}
+
+// RequestValidationMiddleware binds path params, query, headers and body into one schema and validates it once.
+func RequestValidationMiddleware(schemaFactory func() interface{}) echo.MiddlewareFunc {
+	return ValidationMiddleware(schemaFactory, ValidationOptions{
+		Source:     ValidationSourceRequest,
+		ContextKey: "validatedRequest",
+	})
+}
+
+// bindRequest binds every request source into schema. Unlike c.Bind, query parameters are bound
+// for all methods and headers are bound too. The body goes first so that path, query and header
+// values cannot be overwritten by same-named body fields.
+func bindRequest(c echo.Context, schema interface{}) error {
+	binder := &echo.DefaultBinder{}
+	if err := binder.BindBody(c, schema); err != nil {
+		return err
+	}
+	if err := binder.BindPathParams(c, schema); err != nil {
+		return err
+	}
+	if err := binder.BindQueryParams(c, schema); err != nil {
+		return err
+	}
+	return binder.BindHeaders(c, schema)
+}
+
+func validateSchema(c echo.Context, schema interface{}, v echo.Validator) error {
+	if v != nil {
+		return v.Validate(schema)
+	}
+	if c.Echo().Validator == nil {
+		return defaultValidator.Validate(schema)
+	}
+	return c.Validate(schema)
+}
+
+// tagKeysForSource returns the struct tags that name fields on the wire for a validation source,
+// in order of preference.
+func tagKeysForSource(c echo.Context, source ValidationSource) []string {
+	switch source {
+	case ValidationSourceQuery:
+		return []string{"query"}
+	case ValidationSourceMultipart:
+		return []string{"form"}
+	case ValidationSourcePath:
+		return []string{"param"}
+	}
+	bodyKey := "json"
+	ctype := c.Request().Header.Get(echo.HeaderContentType)
+	if strings.HasPrefix(ctype, echo.MIMEApplicationForm) || strings.HasPrefix(ctype, echo.MIMEMultipartForm) {
+		bodyKey = "form"
+	}
+	if source == ValidationSourceRequest {
+		return []string{"param", "query", "header", bodyKey}
 	}
+	return []string{bodyKey}
 }
@@ -0,0 +1,319 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+)
+
+// ContractOptions configures ContractMiddleware.
+type ContractOptions struct {
+	// ValidateResponses buffers every response and checks it against the spec. A violation is
+	// logged and replaced with a 500. Meant for dev and test; streaming responses do not work with it.
+	ValidateResponses bool
+	// AllowUnknownRoutes passes requests without a matching operation to the next handler
+	// instead of failing with 404/405.
+	AllowUnknownRoutes bool
+	// AuthenticationFunc checks security requirements of the spec. When nil they are not checked:
+	// auth middlewares already do that job.
+	AuthenticationFunc openapi3filter.AuthenticationFunc
+	Skipper            func(c echo.Context) bool
+}
+
+// LoadSpec loads and validates an OpenAPI 3.0 document from a YAML or JSON file.
+func LoadSpec(path string) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec %s: %w", path, err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// LoadSpecData is LoadSpec for an in-memory document, e.g. one embedded with go:embed.
+func LoadSpecData(data []byte) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	return doc, nil
+}
+
+// ContractMiddleware validates path, query, header parameters and the body of every request against
+// the operation doc defines for it (contract-first mode). Violations are reported as
+// schemas.ValidationError: 422 when values break the schema, 400 when they cannot be parsed.
+// Only the path of server URLs is used for matching, so the spec may list public hosts.
+func ContractMiddleware(doc *openapi3.T, opts ContractOptions) (echo.MiddlewareFunc, error) {
+	router, err := gorillamux.NewRouter(withRelativeServers(doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI router: %w", err)
+	}
+
+	filterOpts := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: opts.AuthenticationFunc,
+	}
+	if filterOpts.AuthenticationFunc == nil {
+		filterOpts.AuthenticationFunc = openapi3filter.NoopAuthenticationFunc
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			route, pathParams, err := router.FindRoute(req)
+			if err != nil {
+				switch {
+				case opts.AllowUnknownRoutes:
+					return next(c)
+				case errors.Is(err, routers.ErrMethodNotAllowed):
+					return echo.ErrMethodNotAllowed
+				default:
+					return echo.ErrNotFound
+				}
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    filterOpts,
+			}
+			if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+				return writeContractError(c, err)
+			}
+
+			if !opts.ValidateResponses {
+				return next(c)
+			}
+			return validateResponse(c, next, input)
+		}
+	}, nil
+}
+
+// withRelativeServers returns a shallow copy of doc whose server URLs keep only their path.
+func withRelativeServers(doc *openapi3.T) *openapi3.T {
+	cp := *doc
+	cp.Servers = make(openapi3.Servers, 0, len(doc.Servers))
+	for _, s := range doc.Servers {
+		server := *s
+		if u, err := url.Parse(s.URL); err == nil && u.Host != "" {
+			server.URL = u.Path
+		}
+		cp.Servers = append(cp.Servers, &server)
+	}
+	return &cp
+}
+
+func writeContractError(c echo.Context, err error) error {
+	var secErr *openapi3filter.SecurityRequirementsError
+	if errors.As(err, &secErr) {
+		return schemas.WriteError(c, http.StatusUnauthorized, schemas.GenError(c, schemas.UNAUTHORIZED, "Unauthorized", nil))
+	}
+
+	status := http.StatusUnprocessableEntity
+	code, message := schemas.VALIDATION_FAILED, "Validation failed"
+	var fields []schemas.FieldError
+	for _, e := range flattenErrors(err) {
+		var reqErr *openapi3filter.RequestError
+		if !errors.As(e, &reqErr) {
+			fields = append(fields, schemas.FieldError{Field: "request", Issue: e.Error()})
+			continue
+		}
+		fieldErrs, schemaViolation := requestFieldErrors(reqErr)
+		if !schemaViolation {
+			status, code, message = http.StatusBadRequest, schemas.BAD_REQUEST, "Invalid request"
+		}
+		fields = append(fields, fieldErrs...)
+	}
+
+	return schemas.WriteError(c, status, schemas.ValidationError{
+		ApiError:    schemas.GenError(c, code, message, nil),
+		FieldErrors: fields,
+	})
+}
+
+// requestFieldErrors converts one RequestError and reports whether it is a schema violation
+// (as opposed to a value that could not be parsed or an unsupported content type).
+func requestFieldErrors(err *openapi3filter.RequestError) ([]schemas.FieldError, bool) {
+	base := "body"
+	if err.Parameter != nil {
+		base = err.Parameter.Name
+	}
+
+	var fields []schemas.FieldError
+	schemaViolation := true
+	for _, e := range flattenErrors(err.Err) {
+		var schemaErr *openapi3.SchemaError
+		switch {
+		case errors.As(e, &schemaErr):
+			fields = append(fields, schemas.FieldError{
+				Field: schemaFieldPath(base, err.Parameter == nil, schemaErr.JSONPointer()),
+				Issue: schemaErr.Reason,
+			})
+		case errors.Is(e, openapi3filter.ErrInvalidRequired), errors.Is(e, openapi3filter.ErrInvalidEmptyValue):
+			fields = append(fields, schemas.FieldError{Field: base, Issue: e.Error()})
+		default:
+			schemaViolation = false
+			fields = append(fields, schemas.FieldError{Field: base, Issue: requestErrorIssue(err, e)})
+		}
+	}
+	if len(fields) == 0 {
+		schemaViolation = false
+		fields = append(fields, schemas.FieldError{Field: base, Issue: requestErrorIssue(err, nil)})
+	}
+	return fields, schemaViolation
+}
+
+func requestErrorIssue(err *openapi3filter.RequestError, cause error) string {
+	if err.Reason != "" {
+		return err.Reason
+	}
+	if cause != nil {
+		return cause.Error()
+	}
+	return err.Error()
+}
+
+// schemaFieldPath renders a JSON pointer as "items[2].price". Body fields drop the "body" prefix.
+func schemaFieldPath(base string, isBody bool, pointer []string) string {
+	var b strings.Builder
+	if !isBody || len(pointer) == 0 {
+		b.WriteString(base)
+	}
+	for _, p := range pointer {
+		if _, err := strconv.Atoi(p); err == nil {
+			b.WriteString("[" + p + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// flattenErrors expands nested openapi3.MultiError values.
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	// not errors.As: MultiError.As matches its elements, which would unwrap RequestError too
+	me, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+	var out []error
+	for _, e := range me {
+		out = append(out, flattenErrors(e)...)
+	}
+	return out
+}
+
+// bufferedResponse holds the status and body until the response has been validated.
+// Headers are written to the underlying writer directly.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func validateResponse(c echo.Context, next echo.HandlerFunc, reqInput *openapi3filter.RequestValidationInput) error {
+	resp := c.Response()
+	orig := resp.Writer
+	buf := &bufferedResponse{ResponseWriter: orig, status: http.StatusOK}
+	resp.Writer = buf
+	err := next(c)
+	resp.Writer = orig
+
+	if err != nil || !resp.Committed {
+		flushResponse(orig, buf, resp.Committed)
+		return err
+	}
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: reqInput,
+		Status:                 buf.status,
+		Header:                 orig.Header(),
+		Options:                reqInput.Options,
+	}
+	input.SetBodyBytes(buf.body.Bytes())
+	verr := openapi3filter.ValidateResponse(c.Request().Context(), input)
+	if verr == nil {
+		flushResponse(orig, buf, true)
+		return nil
+	}
+
+	var fields []schemas.FieldError
+	for _, e := range flattenErrors(verr) {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(e, &schemaErr) {
+			fields = append(fields, schemas.FieldError{
+				Field: schemaFieldPath("body", true, schemaErr.JSONPointer()),
+				Issue: schemaErr.Reason,
+			})
+			continue
+		}
+		fields = append(fields, schemas.FieldError{Field: "response", Issue: e.Error()})
+	}
+	middleware.Logger(c).LogAttrs(context.WithoutCancel(c.Request().Context()), slog.LevelError, "RESPONSE_CONTRACT_VIOLATION",
+		slog.String("method", c.Request().Method),
+		slog.String("path", c.Path()),
+		slog.Int("status", buf.status),
+		slog.String("error", verr.Error()),
+	)
+
+	resp.Committed = false
+	resp.Size = 0
+	orig.Header().Del(echo.HeaderContentLength)
+	orig.Header().Del(echo.HeaderContentType)
+	return schemas.WriteError(c, http.StatusInternalServerError, schemas.ValidationError{
+		ApiError:    schemas.GenError(c, schemas.INTERNAL_SERVER_ERROR, "Response does not match the API contract", nil),
+		FieldErrors: fields,
+	})
+}
+
+func flushResponse(w http.ResponseWriter, buf *bufferedResponse, committed bool) {
+	if !committed {
+		return
+	}
+	w.WriteHeader(buf.status)
+	if _, err := io.Copy(w, &buf.body); err != nil {
+		slog.LogAttrs(context.Background(), slog.LevelWarn, "RESPONSE_WRITE_FAILED", slog.String("error", err.Error()))
+	}
+}
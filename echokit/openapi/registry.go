@@ -0,0 +1,381 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/config"
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+)
+
+// Auth couples an auth middleware with the security scheme it implements.
+type Auth struct {
+	Scheme     string
+	Definition *SecurityScheme
+	Middleware echo.MiddlewareFunc
+}
+
+// JWTAuth is middleware.JWTMiddleware documented as an HTTP bearer scheme.
+func JWTAuth(cfg config.JWTConfig) Auth {
+	return Auth{
+		Scheme:     "bearerAuth",
+		Definition: &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		Middleware: middleware.JWTMiddleware(cfg),
+	}
+}
+
+// TGAuth is middleware.TGMiddleware documented as an Authorization header with Telegram init data.
+func TGAuth(cfg config.TgWebAppConfig) Auth {
+	return Auth{
+		Scheme: "tmaAuth",
+		Definition: &SecurityScheme{
+			Type:        "apiKey",
+			Name:        "Authorization",
+			In:          "header",
+			Description: "Telegram Mini App init data: \"tma <initData>\"",
+		},
+		Middleware: middleware.TGMiddleware(cfg),
+	}
+}
+
+// Route describes one endpoint. Schema factories are the same ones passed to the validation
+// middlewares; Add installs those middlewares and records the schemas in the document.
+type Route struct {
+	Summary     string
+	Description string
+	OperationID string
+	Tags        []string
+
+	Body      func() interface{} // JSON body, BodyValidationMiddleware
+	Query     func() interface{} // QueryValidationMiddleware
+	Multipart func() interface{} // MultipartValidationMiddleware
+	Request   func() interface{} // RequestValidationMiddleware (param/query/header/json)
+
+	// Responses maps a status to a value of the response type; nil means no body.
+	// When empty, a bare 200 response is documented.
+	Responses map[int]interface{}
+
+	Auth        []Auth
+	Middlewares []echo.MiddlewareFunc
+}
+
+type router interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+	Group(prefix string, m ...echo.MiddlewareFunc) *echo.Group
+}
+
+// Registry registers Echo routes and builds an OpenAPI 3.1 document describing them.
+type Registry struct {
+	router router
+	prefix string
+	auth   []Auth
+	state  *state
+}
+
+type state struct {
+	mu  sync.Mutex
+	doc *Document
+	gen *generator
+	// json caches the encoded document until the next route is documented.
+	json []byte
+}
+
+func New(e *echo.Echo, info Info, servers ...Server) *Registry {
+	return &Registry{
+		router: e,
+		state: &state{
+			doc: &Document{
+				OpenAPI: Version,
+				Info:    info,
+				Servers: servers,
+				Paths:   make(map[string]*PathItem),
+			},
+			gen: newGenerator(),
+		},
+	}
+}
+
+// Group returns a Registry whose routes live under prefix.
+func (r *Registry) Group(prefix string, m ...echo.MiddlewareFunc) *Registry {
+	return &Registry{
+		router: r.router.Group(prefix, m...),
+		prefix: r.prefix + prefix,
+		auth:   r.auth,
+		state:  r.state,
+	}
+}
+
+// WithAuth returns a Registry that applies and documents auth on every route it adds.
+func (r *Registry) WithAuth(auth ...Auth) *Registry {
+	cp := *r
+	cp.auth = append(append([]Auth(nil), r.auth...), auth...)
+	return &cp
+}
+
+func (r *Registry) GET(path string, h echo.HandlerFunc, route Route) *echo.Route {
+	return r.Add(http.MethodGet, path, h, route)
+}
+
+func (r *Registry) POST(path string, h echo.HandlerFunc, route Route) *echo.Route {
+	return r.Add(http.MethodPost, path, h, route)
+}
+
+func (r *Registry) PUT(path string, h echo.HandlerFunc, route Route) *echo.Route {
+	return r.Add(http.MethodPut, path, h, route)
+}
+
+func (r *Registry) PATCH(path string, h echo.HandlerFunc, route Route) *echo.Route {
+	return r.Add(http.MethodPatch, path, h, route)
+}
+
+func (r *Registry) DELETE(path string, h echo.HandlerFunc, route Route) *echo.Route {
+	return r.Add(http.MethodDelete, path, h, route)
+}
+
+// Add registers the route in Echo with its middlewares (custom, auth, then validation) and documents it.
+func (r *Registry) Add(method, path string, h echo.HandlerFunc, route Route) *echo.Route {
+	auth := append(append([]Auth(nil), r.auth...), route.Auth...)
+
+	mws := append([]echo.MiddlewareFunc(nil), route.Middlewares...)
+	for _, a := range auth {
+		mws = append(mws, a.Middleware)
+	}
+	if route.Request != nil {
+		mws = append(mws, middleware.RequestValidationMiddleware(route.Request))
+	}
+	if route.Query != nil {
+		mws = append(mws, middleware.QueryValidationMiddleware(route.Query))
+	}
+	if route.Body != nil {
+		mws = append(mws, middleware.BodyValidationMiddleware(route.Body))
+	}
+	if route.Multipart != nil {
+		mws = append(mws, middleware.MultipartValidationMiddleware(route.Multipart))
+	}
+
+	r.document(method, r.prefix+path, route, auth)
+	return r.router.Add(method, path, h, mws...)
+}
+
+// Document returns a copy of the generated document; later registrations do not change it.
+func (r *Registry) Document() (*Document, error) {
+	data, err := r.state.encode()
+	if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to copy OpenAPI document: %w", err)
+	}
+	return &doc, nil
+}
+
+// Handler serves the document as JSON.
+func (r *Registry) Handler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		data, err := r.state.encode()
+		if err != nil {
+			return err
+		}
+		return c.JSONBlob(http.StatusOK, data)
+	}
+}
+
+// encode marshals the document while holding the lock, so it never races with Add.
+func (st *state) encode() ([]byte, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.json == nil {
+		st.doc.Components.Schemas = st.gen.components
+		data, err := json.Marshal(st.doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+		}
+		st.json = data
+	}
+	return st.json, nil
+}
+
+// Serve exposes the document at path (e.g. "/openapi.json"); the route itself is not documented.
+func (r *Registry) Serve(path string) *echo.Route {
+	return r.router.Add(http.MethodGet, path, r.Handler())
+}
+
+func (r *Registry) document(method, path string, route Route, auth []Auth) {
+	st := r.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.json = nil
+
+	op := &Operation{
+		Tags:        route.Tags,
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: route.OperationID,
+		Responses:   make(map[string]*Response),
+	}
+
+	openAPIPath, pathParams := convertPath(path)
+	hasInput := false
+
+	if route.Request != nil {
+		hasInput = true
+		t := derefType(reflect.TypeOf(route.Request()))
+		op.Parameters = append(op.Parameters, st.gen.parameters(t, "param", "path")...)
+		op.Parameters = append(op.Parameters, st.gen.parameters(t, "query", "query")...)
+		op.Parameters = append(op.Parameters, st.gen.parameters(t, "header", "header")...)
+		if body := st.gen.bodyFields(t); body != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				echo.MIMEApplicationJSON: {Schema: body},
+			}}
+		}
+	}
+	if route.Query != nil {
+		hasInput = true
+		t := derefType(reflect.TypeOf(route.Query()))
+		op.Parameters = append(op.Parameters, st.gen.parameters(t, "query", "query")...)
+	}
+	if route.Body != nil {
+		hasInput = true
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			echo.MIMEApplicationJSON: {Schema: st.gen.schemaFor(reflect.TypeOf(route.Body()), []string{"json"})},
+		}}
+	}
+	if route.Multipart != nil {
+		hasInput = true
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			echo.MIMEMultipartForm: {Schema: st.gen.schemaFor(reflect.TypeOf(route.Multipart()), []string{"form"})},
+		}}
+	}
+
+	for _, name := range pathParams {
+		if !hasParameter(op.Parameters, name, "path") {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	for status, v := range route.Responses {
+		resp := &Response{Description: http.StatusText(status)}
+		if v != nil {
+			resp.Content = map[string]MediaType{
+				echo.MIMEApplicationJSON: {Schema: st.gen.schemaFor(reflect.TypeOf(v), []string{"json"})},
+			}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	if len(route.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	apiError := reflect.TypeOf(schemas.ApiError{})
+	validationError := reflect.TypeOf(schemas.ValidationError{})
+	if hasInput || len(pathParams) > 0 {
+		st.addErrorResponse(op, http.StatusBadRequest, validationError)
+		st.addErrorResponse(op, http.StatusUnprocessableEntity, validationError)
+	}
+	if len(auth) > 0 {
+		st.addErrorResponse(op, http.StatusUnauthorized, apiError)
+	}
+	st.addErrorResponse(op, http.StatusInternalServerError, apiError)
+
+	for _, a := range auth {
+		if st.doc.Components.SecuritySchemes == nil {
+			st.doc.Components.SecuritySchemes = make(map[string]*SecurityScheme)
+		}
+		st.doc.Components.SecuritySchemes[a.Scheme] = a.Definition
+		op.Security = append(op.Security, map[string][]string{a.Scheme: {}})
+	}
+
+	item, ok := st.doc.Paths[openAPIPath]
+	if !ok {
+		item = &PathItem{}
+		st.doc.Paths[openAPIPath] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+func (st *state) addErrorResponse(op *Operation, status int, t reflect.Type) {
+	key := strconv.Itoa(status)
+	if _, ok := op.Responses[key]; ok {
+		return
+	}
+	op.Responses[key] = &Response{
+		Description: http.StatusText(status),
+		Content: map[string]MediaType{
+			echo.MIMEApplicationJSON: {Schema: st.gen.schemaFor(t, []string{"json"})},
+		},
+	}
+}
+
+// parameters documents the fields of t tagged with tagKey as parameters located in "in".
+func (g *generator) parameters(t reflect.Type, tagKey, in string) []Parameter {
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get(tagKey), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		s := g.schemaFor(f.Type, []string{tagKey})
+		required := applyValidate(s, f.Type, f.Tag.Get("validate"))
+		params = append(params, Parameter{Name: name, In: in, Required: required || in == "path", Schema: s})
+	}
+	return params
+}
+
+// bodyFields builds the JSON body schema of a combined request from fields without param/query/header tags.
+func (g *generator) bodyFields(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("param") != "" || f.Tag.Get("query") != "" || f.Tag.Get("header") != "" {
+			continue
+		}
+		name := middleware.TagFieldName(f, "json")
+		if f.Tag.Get("json") == "-" {
+			continue
+		}
+		prop := g.schemaFor(f.Type, []string{"json"})
+		if applyValidate(prop, f.Type, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+	if len(s.Properties) == 0 {
+		return nil
+	}
+	return s
+}
+
+// convertPath turns "/users/:id" into "/users/{id}" and returns the parameter names.
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			params = append(params, seg[1:])
+			segments[i] = "{" + seg[1:] + "}"
+		case seg == "*":
+			params = append(params, "*")
+			segments[i] = "{*}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func hasParameter(params []Parameter, name, in string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
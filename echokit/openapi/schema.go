@@ -0,0 +1,279 @@
+package openapi
+
+import (
+	"encoding"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	fileHeaderType      = reflect.TypeOf(multipart.FileHeader{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// generator converts Go types into schemas. Named structs reached through `json` tags are stored in
+// components and referenced; other sources (query, form) are inlined because their names differ.
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+func isJSONKeys(tagKeys []string) bool {
+	return len(tagKeys) == 1 && tagKeys[0] == "json"
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func (g *generator) schemaFor(t reflect.Type, tagKeys []string) *Schema {
+	return g.schemaForDepth(t, tagKeys, 0)
+}
+
+func (g *generator) schemaForDepth(t reflect.Type, tagKeys []string, depth int) *Schema {
+	t = derefType(t)
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == fileHeaderType:
+		return &Schema{Type: "string", ContentMediaType: "application/octet-stream"}
+	case t.Kind() != reflect.String && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)):
+		s := &Schema{Type: "string"}
+		if strings.HasSuffix(t.PkgPath(), "uuid") {
+			s.Format = "uuid"
+		}
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaForDepth(t.Elem(), tagKeys, depth+1)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaForDepth(t.Elem(), tagKeys, depth+1)}
+	case reflect.Struct:
+		if isJSONKeys(tagKeys) && t.Name() != "" {
+			return &Schema{Ref: "#/components/schemas/" + g.component(t)}
+		}
+		if depth > 16 {
+			return &Schema{Type: "object"}
+		}
+		return g.objectSchema(t, tagKeys, depth)
+	}
+	// interface{} and anything else: any value
+	return &Schema{}
+}
+
+// component registers a named struct in components and returns its name.
+func (g *generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.components[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	g.names[t] = name
+	g.components[name] = &Schema{} // placeholder for recursive types
+	*g.components[name] = *g.objectSchema(t, []string{"json"}, 0)
+	return name
+}
+
+func (g *generator) objectSchema(t reflect.Type, tagKeys []string, depth int) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t, tagKeys, depth)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type, tagKeys []string, depth int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		if hasSkipTag(f, tagKeys) {
+			continue
+		}
+		name := middleware.TagFieldName(f, tagKeys...)
+		if f.Anonymous && name == f.Name && derefType(f.Type).Kind() == reflect.Struct {
+			g.addFields(s, derefType(f.Type), tagKeys, depth)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		prop := g.schemaForDepth(f.Type, tagKeys, depth+1)
+		if applyValidate(prop, f.Type, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+func hasSkipTag(f reflect.StructField, tagKeys []string) bool {
+	for _, key := range tagKeys {
+		if f.Tag.Get(key) == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyValidate maps go-playground/validator rules onto schema constraints and reports whether
+// the field is required. Rules after "dive" apply to slice items or map values.
+func applyValidate(s *Schema, t reflect.Type, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			elem := derefType(t)
+			if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array || elem.Kind() == reflect.Map {
+				target := s.Items
+				if elem.Kind() == reflect.Map {
+					target = s.AdditionalProperties
+				}
+				if target != nil {
+					applyValidate(target, elem.Elem(), strings.Join(rules[i+1:], ","))
+				}
+			}
+			return required
+		default:
+			applyRule(s, derefType(t), name, param)
+		}
+	}
+	return required
+}
+
+func applyRule(s *Schema, t reflect.Type, name, param string) {
+	kind := t.Kind()
+	isNumber := kind >= reflect.Int && kind <= reflect.Float64
+	isString := kind == reflect.String
+	isList := kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+
+	switch name {
+	case "min", "max", "len":
+		switch {
+		case isString:
+			if n, err := strconv.ParseInt(param, 10, 64); err == nil {
+				if name != "max" {
+					s.MinLength = &n
+				}
+				if name != "min" {
+					s.MaxLength = &n
+				}
+			}
+		case isList:
+			if n, err := strconv.ParseInt(param, 10, 64); err == nil {
+				if name != "max" {
+					s.MinItems = &n
+				}
+				if name != "min" {
+					s.MaxItems = &n
+				}
+			}
+		case isNumber:
+			if f, err := strconv.ParseFloat(param, 64); err == nil {
+				if name != "max" {
+					s.Minimum = &f
+				}
+				if name != "min" {
+					s.Maximum = &f
+				}
+			}
+		}
+	case "gt", "gte", "lt", "lte":
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil || !isNumber {
+			return
+		}
+		switch name {
+		case "gt":
+			s.ExclusiveMinimum = &f
+		case "gte":
+			s.Minimum = &f
+		case "lt":
+			s.ExclusiveMaximum = &f
+		case "lte":
+			s.Maximum = &f
+		}
+	case "email":
+		s.Format = "email"
+	case "url", "uri", "http_url":
+		s.Format = "uri"
+	case "uuid", "uuid4", "uuid7":
+		s.Format = "uuid"
+	case "datetime":
+		s.Format = "date-time"
+	case "ipv4", "ipv6", "hostname":
+		s.Format = name
+	case "regex":
+		s.Pattern = param
+	case "oneof":
+		for _, v := range strings.Fields(param) {
+			if isNumber {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					s.Enum = append(s.Enum, f)
+					continue
+				}
+			}
+			s.Enum = append(s.Enum, v)
+		}
+	case "filetype":
+		types := strings.FieldsFunc(param, func(r rune) bool { return r == ';' || r == ',' })
+		if len(types) == 1 && s.Type == "string" {
+			s.ContentMediaType = types[0]
+		}
+		addDescription(s, "Allowed types: "+strings.Join(types, ", "))
+	case "filesize":
+		addDescription(s, "Max size: "+param)
+	}
+}
+
+func addDescription(s *Schema, text string) {
+	if s.Description == "" {
+		s.Description = text
+		return
+	}
+	s.Description += ". " + text
+}
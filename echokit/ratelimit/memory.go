@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps counters in process memory. Limits are per replica; use RedisStore when
+// the service runs more than one instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	expires time.Time
+	// fixed window
+	window int64
+	count  int
+	// sliding log
+	log []time.Time
+	// token bucket
+	tokens float64
+	last   time.Time
+	// gcra
+	tat time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, alg Algorithm, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	key = key + ":" + string(alg)
+	e, ok := s.entries[key]
+	if !ok || now.After(e.expires) {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+
+	switch alg {
+	case FixedWindow:
+		return e.fixedWindow(now, limit), nil
+	case SlidingLog:
+		return e.slidingLog(now, limit), nil
+	case TokenBucket:
+		return e.tokenBucket(now, limit), nil
+	case GCRA:
+		return e.gcra(now, limit), nil
+	}
+	return Result{}, fmt.Errorf("unknown rate limit algorithm %q", alg)
+}
+
+func (e *memoryEntry) fixedWindow(now time.Time, l Limit) Result {
+	window := now.UnixNano() / int64(l.Period)
+	if e.window != window {
+		e.window, e.count = window, 0
+	}
+	reset := time.Duration((window+1)*int64(l.Period) - now.UnixNano())
+	e.expires = now.Add(reset)
+
+	res := Result{Limit: l.Rate, ResetAfter: reset}
+	if e.count >= l.Rate {
+		res.RetryAfter = reset
+		return res
+	}
+	e.count++
+	res.Allowed = true
+	res.Remaining = l.Rate - e.count
+	return res
+}
+
+func (e *memoryEntry) slidingLog(now time.Time, l Limit) Result {
+	cutoff := now.Add(-l.Period)
+	i := 0
+	for i < len(e.log) && !e.log[i].After(cutoff) {
+		i++
+	}
+	e.log = e.log[i:]
+
+	res := Result{Limit: l.Rate}
+	if len(e.log) >= l.Rate {
+		res.RetryAfter = e.log[len(e.log)-l.Rate].Add(l.Period).Sub(now)
+		res.ResetAfter = e.log[len(e.log)-1].Add(l.Period).Sub(now)
+		return res
+	}
+	e.log = append(e.log, now)
+	e.expires = now.Add(l.Period)
+	res.Allowed = true
+	res.Remaining = l.Rate - len(e.log)
+	res.ResetAfter = l.Period
+	return res
+}
+
+func (e *memoryEntry) tokenBucket(now time.Time, l Limit) Result {
+	burst := float64(l.burst())
+	perToken := float64(l.Period) / float64(l.Rate)
+	if e.last.IsZero() {
+		e.tokens = burst
+	} else {
+		e.tokens = math.Min(burst, e.tokens+float64(now.Sub(e.last))/perToken)
+	}
+	e.last = now
+
+	res := Result{Limit: l.burst()}
+	if e.tokens >= 1 {
+		e.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - e.tokens) * perToken)
+	}
+	res.Remaining = int(e.tokens)
+	res.ResetAfter = time.Duration((burst - e.tokens) * perToken)
+	e.expires = now.Add(res.ResetAfter)
+	return res
+}
+
+func (e *memoryEntry) gcra(now time.Time, l Limit) Result {
+	interval := l.Period / time.Duration(l.Rate)
+	tolerance := interval * time.Duration(l.burst())
+	tat := e.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(interval)
+
+	res := Result{Limit: l.burst()}
+	if allowAt := newTat.Add(-tolerance); now.Before(allowAt) {
+		res.RetryAfter = allowAt.Sub(now)
+		res.Remaining = 0
+		res.ResetAfter = tat.Sub(now)
+		return res
+	}
+	e.tat = newTat
+	e.expires = newTat
+	res.Allowed = true
+	res.Remaining = int((tolerance - newTat.Sub(now)) / interval)
+	res.ResetAfter = newTat.Sub(now)
+	return res
+}
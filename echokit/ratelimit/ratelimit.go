@@ -0,0 +1,212 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+)
+
+type Algorithm string
+
+const (
+	// FixedWindow counts requests per calendar window of Period; cheap, but allows 2x Rate at window edges.
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingLog keeps a timestamp per request and counts the ones within the last Period; exact, O(Rate) memory.
+	SlidingLog Algorithm = "sliding_log"
+	// TokenBucket refills Rate tokens per Period up to Burst.
+	TokenBucket Algorithm = "token_bucket"
+	// GCRA (generic cell rate algorithm) behaves like TokenBucket but stores a single timestamp.
+	GCRA Algorithm = "gcra"
+)
+
+// Limit allows Rate requests per Period. Burst caps bursts for TokenBucket and GCRA, default Rate.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+func PerSecond(rate int) Limit { return Limit{Rate: rate, Period: time.Second} }
+func PerMinute(rate int) Limit { return Limit{Rate: rate, Period: time.Minute} }
+func PerHour(rate int) Limit   { return Limit{Rate: rate, Period: time.Hour} }
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// Result is the outcome of one Allow call.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the quota is fully available again.
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next request would be allowed; zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Store counts requests for a key. Implementations must be safe for concurrent use.
+type Store interface {
+	Allow(ctx context.Context, key string, alg Algorithm, limit Limit) (Result, error)
+}
+
+// KeyFunc identifies the client a request is counted against; "" exempts the request.
+type KeyFunc func(c echo.Context) string
+
+// KeyByIP uses the client IP as resolved by Echo (see Echo.IPExtractor).
+func KeyByIP() KeyFunc {
+	return func(c echo.Context) string {
+		return "ip:" + c.RealIP()
+	}
+}
+
+// KeyByUser uses the "userID" set by JWTMiddleware or TGMiddleware and falls back to the IP
+// for anonymous requests.
+func KeyByUser() KeyFunc {
+	return func(c echo.Context) string {
+		if id := c.Get("userID"); id != nil {
+			return fmt.Sprintf("user:%v", id)
+		}
+		return "ip:" + c.RealIP()
+	}
+}
+
+// KeyByHeader uses a header such as "X-Api-Key". The value is hashed so keys never reach the store;
+// requests without the header are exempt, so combine it with an IP limiter if needed.
+func KeyByHeader(name string) KeyFunc {
+	return func(c echo.Context) string {
+		v := c.Request().Header.Get(name)
+		if v == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(v))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+}
+
+// KeyByRoute uses the method and route template, i.e. one shared limit per endpoint.
+func KeyByRoute() KeyFunc {
+	return func(c echo.Context) string {
+		return "route:" + c.Request().Method + " " + c.Path()
+	}
+}
+
+// Keys joins several keys, e.g. Keys(KeyByRoute(), KeyByIP()) limits each IP per endpoint.
+func Keys(fns ...KeyFunc) KeyFunc {
+	return func(c echo.Context) string {
+		parts := make([]string, 0, len(fns))
+		for _, fn := range fns {
+			k := fn(c)
+			if k == "" {
+				return ""
+			}
+			parts = append(parts, k)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+type Options struct {
+	Store     Store
+	Algorithm Algorithm // default SlidingLog
+	Limit     Limit
+	// KeyFunc default KeyByIP.
+	KeyFunc KeyFunc
+	// Prefix separates limiters sharing a store, default "ratelimit".
+	Prefix  string
+	Skipper func(c echo.Context) bool
+	// FailClosed rejects requests with 503 when the store fails; by default they are let through.
+	FailClosed bool
+}
+
+// Middleware limits requests per key and reports the quota in RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers. Rejected requests get 429 with Retry-After and
+// a schemas.ApiError body.
+func Middleware(opts Options) echo.MiddlewareFunc {
+	if opts.Store == nil {
+		panic("ratelimit: Options.Store is required")
+	}
+	if opts.Limit.Rate <= 0 || opts.Limit.Period <= 0 {
+		panic("ratelimit: Options.Limit needs a positive Rate and Period")
+	}
+	if opts.Algorithm == "" {
+		opts.Algorithm = SlidingLog
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = KeyByIP()
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "ratelimit"
+	}
+	policy := policyHeader(opts.Algorithm, opts.Limit)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+			key := opts.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			res, err := opts.Store.Allow(ctx, opts.Prefix+":"+key, opts.Algorithm, opts.Limit)
+			if err != nil {
+				middleware.Logger(c).LogAttrs(ctx, slog.LevelWarn, "RATE_LIMIT_STORE_FAILED",
+					slog.String("error", err.Error()),
+				)
+				if opts.FailClosed {
+					return schemas.WriteError(c, http.StatusServiceUnavailable,
+						schemas.GenError(c, schemas.ErrorCodeForStatus(http.StatusServiceUnavailable), "Rate limiter unavailable", nil))
+				}
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			header.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(res.ResetAfter), 10))
+			header.Set("RateLimit-Policy", policy)
+
+			if !res.Allowed {
+				retryAfter := ceilSeconds(res.RetryAfter)
+				header.Set(echo.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+				return schemas.WriteError(c, http.StatusTooManyRequests,
+					schemas.GenError(c, schemas.TOO_MANY_REQUESTS, "Too many requests", map[string]interface{}{
+						"retryAfter": retryAfter,
+					}))
+			}
+			return next(c)
+		}
+	}
+}
+
+func policyHeader(alg Algorithm, l Limit) string {
+	p := fmt.Sprintf("%d;w=%d", l.Rate, ceilSeconds(l.Period))
+	if (alg == TokenBucket || alg == GCRA) && l.burst() != l.Rate {
+		p += fmt.Sprintf(";burst=%d", l.burst())
+	}
+	return p
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(d.Seconds()))
+}
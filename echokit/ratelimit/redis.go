@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	rediskit "github.com/nrf24l01/go-web-utils/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Every script touches only KEYS[1], reads the clock with TIME so replicas agree on it, works in
+// milliseconds and returns {allowed, remaining, reset_ms, retry_ms}.
+const luaNow = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+`
+
+// ARGV: period_ms, rate
+var fixedWindowScript = goredis.NewScript(luaNow + `
+local period, rate = tonumber(ARGV[1]), tonumber(ARGV[2])
+local window = math.floor(now / period)
+local reset = (window + 1) * period - now
+local state = redis.call('HMGET', KEYS[1], 'w', 'c')
+local count = 0
+if tonumber(state[1]) == window then count = tonumber(state[2]) end
+if count >= rate then
+	return {0, 0, reset, reset}
+end
+count = count + 1
+redis.call('HSET', KEYS[1], 'w', window, 'c', count)
+redis.call('PEXPIRE', KEYS[1], reset)
+return {1, rate - count, reset, 0}
+`)
+
+// ARGV: period_ms, rate, member nonce
+var slidingLogScript = goredis.NewScript(luaNow + `
+local period, rate = tonumber(ARGV[1]), tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - period)
+local count = redis.call('ZCARD', KEYS[1])
+if count >= rate then
+	local oldest = redis.call('ZRANGE', KEYS[1], count - rate, count - rate, 'WITHSCORES')
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	return {0, 0, tonumber(newest[2]) + period - now, tonumber(oldest[2]) + period - now}
+end
+redis.call('ZADD', KEYS[1], now, now .. '-' .. ARGV[3])
+redis.call('PEXPIRE', KEYS[1], period)
+return {1, rate - count - 1, period, 0}
+`)
+
+// ARGV: period_ms, rate, burst
+var tokenBucketScript = goredis.NewScript(luaNow + `
+local per_token, burst = tonumber(ARGV[1]) / tonumber(ARGV[2]), tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = burst
+if state[1] then
+	tokens = math.min(burst, tonumber(state[1]) + (now - tonumber(state[2])) / per_token)
+end
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * per_token)
+end
+local reset = math.ceil((burst - tokens) * per_token)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', now)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+// ARGV: period_ms, rate, burst
+var gcraScript = goredis.NewScript(luaNow + `
+local interval = tonumber(ARGV[1]) / tonumber(ARGV[2])
+local tolerance = interval * tonumber(ARGV[3])
+local tat = tonumber(redis.call('GET', KEYS[1]) or '0')
+if tat < now then tat = now end
+local new_tat = tat + interval
+local allow_at = new_tat - tolerance
+if now < allow_at then
+	return {0, 0, math.ceil(tat - now), math.ceil(allow_at - now)}
+end
+redis.call('SET', KEYS[1], tostring(new_tat), 'PX', math.ceil(new_tat - now))
+return {1, math.floor((tolerance - (new_tat - now)) / interval), math.ceil(new_tat - now), 0}
+`)
+
+// RedisStore keeps counters in Redis so every replica shares the same limits. Each check is a single
+// Lua script call, so it is atomic and works on Redis Cluster.
+type RedisStore struct {
+	client goredis.Scripter
+}
+
+func NewRedisStore(client *rediskit.RedisClient) *RedisStore {
+	return &RedisStore{client: client.Client}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, alg Algorithm, limit Limit) (Result, error) {
+	key = key + ":" + string(alg)
+	period := limit.Period.Milliseconds()
+	if period < 1 {
+		period = 1
+	}
+
+	var (
+		raw interface{}
+		err error
+	)
+	switch alg {
+	case FixedWindow:
+		raw, err = fixedWindowScript.Run(ctx, s.client, []string{key}, period, limit.Rate).Result()
+	case SlidingLog:
+		raw, err = slidingLogScript.Run(ctx, s.client, []string{key}, period, limit.Rate, uuid.NewString()).Result()
+	case TokenBucket:
+		raw, err = tokenBucketScript.Run(ctx, s.client, []string{key}, period, limit.Rate, limit.burst()).Result()
+	case GCRA:
+		raw, err = gcraScript.Run(ctx, s.client, []string{key}, period, limit.Rate, limit.burst()).Result()
+	default:
+		return Result{}, fmt.Errorf("unknown rate limit algorithm %q", alg)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+
+	values, ok := raw.([]interface{})
+	if !ok || len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", raw)
+	}
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		if ints[i], ok = v.(int64); !ok {
+			return Result{}, fmt.Errorf("unexpected rate limit script result %v", raw)
+		}
+	}
+
+	res := Result{
+		Allowed:    ints[0] == 1,
+		Limit:      limit.Rate,
+		Remaining:  int(ints[1]),
+		ResetAfter: time.Duration(ints[2]) * time.Millisecond,
+		RetryAfter: time.Duration(ints[3]) * time.Millisecond,
+	}
+	if alg == TokenBucket || alg == GCRA {
+		res.Limit = limit.burst()
+	}
+	return res, nil
+}
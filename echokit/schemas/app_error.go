@@ -0,0 +1,144 @@
+package schemas
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AppError is a domain error that carries its own ErrorCode and HTTP status.
+// Handlers return it and the Echo error handler renders it as ApiError
+// (or ValidationError when FieldErrors are set).
+//
+// Two AppErrors match with errors.Is when their codes are equal, so sentinels like
+// ErrNotFound can be compared after wrapping: errors.Is(err, schemas.ErrNotFound).
+type AppError struct {
+	Code        ErrorCode
+	Status      int    // 0 takes the status registered for Code when the error is rendered
+	Message     string // public message, sent to the client
+	Err         error  // private cause, only logged
+	Details     map[string]interface{}
+	FieldErrors []FieldError
+}
+
+var (
+	ErrBadRequest         = NewError(BAD_REQUEST, "Bad Request")
+	ErrUnauthorized       = NewError(UNAUTHORIZED, "Unauthorized")
+	ErrForbidden          = NewError(FORBIDDEN, "Forbidden")
+	ErrNotFound           = NewError(NOT_FOUND, "Not Found")
+	ErrUserNotFound       = NewError(USER_NOT_FOUND, "User not found")
+	ErrEmailAlreadyExists = NewError(EMAIL_ALREADY_EXISTS, "Email already exists")
+	ErrConflict           = NewError(CONFLICT, "Conflict")
+	ErrTooManyRequests    = NewError(TOO_MANY_REQUESTS, "Too many requests")
+	ErrInternal           = NewError(INTERNAL_SERVER_ERROR, "Internal Server Error")
+)
+
+var (
+	codeStatusMu sync.RWMutex
+	codeStatus   = map[ErrorCode]int{
+		BAD_REQUEST:           http.StatusBadRequest,
+		VALIDATION_FAILED:     http.StatusUnprocessableEntity,
+		UNAUTHORIZED:          http.StatusUnauthorized,
+		FORBIDDEN:             http.StatusForbidden,
+		NOT_FOUND:             http.StatusNotFound,
+		USER_NOT_FOUND:        http.StatusNotFound,
+		EMAIL_ALREADY_EXISTS:  http.StatusConflict,
+		CONFLICT:              http.StatusConflict,
+		TIMEOUT:               http.StatusGatewayTimeout,
+		TOO_MANY_REQUESTS:     http.StatusTooManyRequests,
+		INTERNAL_SERVER_ERROR: http.StatusInternalServerError,
+	}
+)
+
+// RegisterErrorCode sets the HTTP status of errors with code and no explicit Status, including the
+// predefined sentinels; it applies to errors created before the call as well.
+func RegisterErrorCode(code ErrorCode, status int) {
+	codeStatusMu.Lock()
+	defer codeStatusMu.Unlock()
+	codeStatus[code] = status
+}
+
+// StatusForCode returns the registered HTTP status for code, or 500 if none is registered.
+func StatusForCode(code ErrorCode) int {
+	codeStatusMu.RLock()
+	defer codeStatusMu.RUnlock()
+	if status, ok := codeStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// NewError creates an AppError whose HTTP status is looked up in the code registry when it is rendered.
+func NewError(code ErrorCode, message string) *AppError {
+	return &AppError{Code: code, Message: message}
+}
+
+// NewAppError creates an AppError with an explicit HTTP status; 0 behaves like NewError.
+func NewAppError(status int, code ErrorCode, message string) *AppError {
+	return &AppError{Code: code, Status: status, Message: message}
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return string(e.Code) + ": " + e.Message + ": " + e.Err.Error()
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an AppError with the same code.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e with err attached as the private cause.
+func (e *AppError) Wrap(err error) *AppError {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+// WithMessage returns a copy of e with a different public message.
+func (e *AppError) WithMessage(message string) *AppError {
+	cp := *e
+	cp.Message = message
+	return &cp
+}
+
+// WithDetails returns a copy of e with details merged into its existing details.
+func (e *AppError) WithDetails(details map[string]interface{}) *AppError {
+	cp := *e
+	cp.Details = make(map[string]interface{}, len(e.Details)+len(details))
+	for k, v := range e.Details {
+		cp.Details[k] = v
+	}
+	for k, v := range details {
+		cp.Details[k] = v
+	}
+	return &cp
+}
+
+// WithFieldErrors returns a copy of e with fieldErrors appended.
+func (e *AppError) WithFieldErrors(fieldErrors ...FieldError) *AppError {
+	cp := *e
+	cp.FieldErrors = append(append([]FieldError(nil), e.FieldErrors...), fieldErrors...)
+	return &cp
+}
+
+// Response builds the HTTP status and the payload (ApiError or ValidationError) for e.
+func (e *AppError) Response(c echo.Context) (int, interface{}) {
+	status := e.Status
+	if status == 0 {
+		status = StatusForCode(e.Code)
+	}
+	apiErr := GenError(c, e.Code, e.Message, e.Details)
+	if len(e.FieldErrors) > 0 {
+		return status, ValidationError{ApiError: apiErr, FieldErrors: e.FieldErrors}
+	}
+	return status, apiErr
+}
@@ -1,6 +1,8 @@
 package schemas
 
 import (
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -16,6 +18,9 @@ const (
 	NOT_FOUND             ErrorCode = "NOT_FOUND"
 	USER_NOT_FOUND        ErrorCode = "USER_NOT_FOUND"
 	EMAIL_ALREADY_EXISTS  ErrorCode = "EMAIL_ALREADY_EXISTS"
+	CONFLICT              ErrorCode = "CONFLICT"
+	TIMEOUT               ErrorCode = "TIMEOUT"
+	TOO_MANY_REQUESTS     ErrorCode = "TOO_MANY_REQUESTS"
 	INTERNAL_SERVER_ERROR ErrorCode = "INTERNAL_SERVER_ERROR"
 )
 
@@ -76,3 +81,34 @@ func GenError(c echo.Context, code ErrorCode, message string, details map[string
 func CustomErrorCode(s string) ErrorCode {
 	return ErrorCode(s)
 }
+
+// ErrorCodeForStatus returns the default ErrorCode for an HTTP status,
+// e.g. 404 -> NOT_FOUND, 405 -> METHOD_NOT_ALLOWED.
+func ErrorCodeForStatus(status int) ErrorCode {
+	switch {
+	case status == http.StatusBadRequest:
+		return BAD_REQUEST
+	case status == http.StatusUnauthorized:
+		return UNAUTHORIZED
+	case status == http.StatusForbidden:
+		return FORBIDDEN
+	case status == http.StatusNotFound:
+		return NOT_FOUND
+	case status == http.StatusConflict:
+		return CONFLICT
+	case status == http.StatusUnprocessableEntity:
+		return VALIDATION_FAILED
+	case status == http.StatusTooManyRequests:
+		return TOO_MANY_REQUESTS
+	case status == http.StatusGatewayTimeout:
+		return TIMEOUT
+	case status >= 500:
+		return INTERNAL_SERVER_ERROR
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return BAD_REQUEST
+	}
+	text = strings.ReplaceAll(text, "-", " ")
+	return ErrorCode(strings.ToUpper(strings.ReplaceAll(text, " ", "_")))
+}
@@ -0,0 +1,148 @@
+package schemas
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ProblemContentType is the RFC 9457 media type.
+const ProblemContentType = "application/problem+json"
+
+// ErrorFormatContextKey holds the ErrorFormat chosen for the current request.
+const ErrorFormatContextKey = "errorFormat"
+
+type ErrorFormat int
+
+const (
+	ErrorFormatJSON      ErrorFormat = iota // ApiError / ValidationError as application/json
+	ErrorFormatProblem                      // RFC 9457 application/problem+json
+	ErrorFormatNegotiate                    // Problem Details only when the Accept header asks for it
+)
+
+// ProblemTypeBaseURI, when set, makes "type" equal to base + lowercased error code
+// (e.g. "https://errors.example.com/not_found"). Otherwise "type" is "about:blank".
+var ProblemTypeBaseURI = ""
+
+// ProblemDetails is the RFC 9457 representation of ApiError; code, traceId, timestamp,
+// details and fieldErrors are extension members.
+type ProblemDetails struct {
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Status      int                    `json:"status"`
+	Detail      string                 `json:"detail,omitempty"`
+	Instance    string                 `json:"instance,omitempty"`
+	Code        ErrorCode              `json:"code,omitempty"`
+	TraceID     string                 `json:"traceId,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+	FieldErrors []FieldError           `json:"fieldErrors,omitempty"`
+}
+
+// Problem converts the error into Problem Details for the given HTTP status.
+func (e ApiError) Problem(status int) ProblemDetails {
+	problemType := "about:blank"
+	if ProblemTypeBaseURI != "" {
+		problemType = strings.TrimSuffix(ProblemTypeBaseURI, "/") + "/" + strings.ToLower(string(e.Code))
+	}
+	title := http.StatusText(status)
+	if title == "" {
+		title = string(e.Code)
+	}
+	return ProblemDetails{
+		Type:      problemType,
+		Title:     title,
+		Status:    status,
+		Detail:    e.Message,
+		Instance:  e.Path,
+		Code:      e.Code,
+		TraceID:   e.TraceID,
+		Timestamp: e.Timestamp,
+		Details:   e.Details,
+	}
+}
+
+// Problem converts the validation error into Problem Details for the given HTTP status.
+func (e ValidationError) Problem(status int) ProblemDetails {
+	p := e.ApiError.Problem(status)
+	p.FieldErrors = e.FieldErrors
+	return p
+}
+
+// WriteError writes an error payload using the format stored under ErrorFormatContextKey.
+// ApiError and ValidationError are converted to Problem Details when that format is selected;
+// any other payload is written as plain JSON.
+func WriteError(c echo.Context, status int, payload interface{}) error {
+	if !wantsProblem(c) {
+		return c.JSON(status, payload)
+	}
+
+	var problem ProblemDetails
+	switch p := payload.(type) {
+	case ApiError:
+		problem = p.Problem(status)
+	case *ApiError:
+		problem = p.Problem(status)
+	case ValidationError:
+		problem = p.Problem(status)
+	case *ValidationError:
+		problem = p.Problem(status)
+	default:
+		return c.JSON(status, payload)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, ProblemContentType)
+	c.Response().WriteHeader(status)
+	return c.Echo().JSONSerializer.Serialize(c, problem, "")
+}
+
+func wantsProblem(c echo.Context) bool {
+	format, _ := c.Get(ErrorFormatContextKey).(ErrorFormat)
+	switch format {
+	case ErrorFormatProblem:
+		return true
+	case ErrorFormatNegotiate:
+		return acceptsProblem(c.Request().Header.Get(echo.HeaderAccept))
+	}
+	return false
+}
+
+// acceptsProblem reports whether the Accept header lists application/problem+json with q > 0 and
+// prefers it at least as much as application/json. The q of application/json comes from its most
+// specific matching range (application/json, application/*, */*).
+func acceptsProblem(accept string) bool {
+	qProblem, qJSON := -1.0, -1.0
+	jsonSpecificity := 0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			q = v
+		}
+		specificity := 0
+		switch mediaType {
+		case ProblemContentType:
+			qProblem = q
+			continue
+		case echo.MIMEApplicationJSON:
+			specificity = 3
+		case "application/*":
+			specificity = 2
+		case "*/*":
+			specificity = 1
+		default:
+			continue
+		}
+		if specificity > jsonSpecificity {
+			qJSON, jsonSpecificity = q, specificity
+		}
+	}
+	return qProblem > 0 && qProblem >= qJSON
+}
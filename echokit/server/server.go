@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	echoMw "github.com/labstack/echo/v4/middleware"
+	gologger "github.com/nrf24l01/go-logger"
+	"github.com/nrf24l01/go-web-utils/config"
+	"github.com/nrf24l01/go-web-utils/echokit/middleware"
+	"github.com/nrf24l01/go-web-utils/echokit/schemas"
+	"github.com/nrf24l01/go-web-utils/echokit/validators"
+)
+
+type Options struct {
+	// Logger enables RequestLogger when set.
+	Logger *gologger.Logger
+	// RequestLog enables StructuredRequestLogger when set; it takes precedence over Logger.
+	RequestLog *middleware.RequestLoggerOptions
+	// ContextLogger enables ContextLoggerMiddleware when set.
+	ContextLogger *middleware.ContextLoggerOptions
+	// Validator is used instead of a fresh validator.New(); file and regex validators are registered on it.
+	Validator *validator.Validate
+	// ErrorFormat selects plain ApiError JSON (default), RFC 9457 Problem Details or Accept negotiation.
+	ErrorFormat schemas.ErrorFormat
+	// Trace configures TraceMiddleware: tracer provider, propagator and ID headers.
+	Trace middleware.TraceOptions
+	// BodyLimitSkipper exempts requests from WebAppConfig.BodyLimit, e.g. SkipRoutes("/files/upload")
+	// for routes using StreamUploadMiddleware, which enforces its own per-file limits.
+	BodyLimitSkipper func(c echo.Context) bool
+	// Middlewares are appended after the default ones.
+	Middlewares []echo.MiddlewareFunc
+}
+
+// New builds an Echo instance configured from WebAppConfig: validator, tracing, request logging,
+// panic recovery, CORS, body limit, server timeouts and the ApiError error handler.
+func New(cfg *config.WebAppConfig, opts Options) (*echo.Echo, error) {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	v := opts.Validator
+	if v == nil {
+		v = validator.New()
+	}
+	if err := validators.RegisterFileValidations(v); err != nil {
+		return nil, err
+	}
+	validators.RegisterRegexValidator(v)
+	if err := validators.RegisterTagNameFunc(v, "json"); err != nil {
+		return nil, err
+	}
+	e.Validator = &middleware.CustomValidator{Validator: v}
+
+	e.HTTPErrorHandler = middleware.HTTPErrorHandler
+
+	e.Server.ReadTimeout = cfg.ReadTimeout
+	e.Server.WriteTimeout = cfg.WriteTimeout
+	e.Server.IdleTimeout = cfg.IdleTimeout
+
+	if opts.ErrorFormat != schemas.ErrorFormatJSON {
+		e.Use(middleware.ErrorFormatMiddleware(opts.ErrorFormat))
+	}
+	if opts.Trace.RequestIDHeader == "" {
+		opts.Trace.RequestIDHeader = echo.HeaderXRequestID
+	}
+	if opts.Trace.TraceIDHeader == "" {
+		opts.Trace.TraceIDHeader = "X-Trace-Id"
+	}
+	e.Use(middleware.TraceMiddlewareWithOptions(opts.Trace))
+	if opts.ContextLogger != nil {
+		e.Use(middleware.ContextLoggerMiddleware(*opts.ContextLogger))
+	}
+	switch {
+	case opts.RequestLog != nil:
+		e.Use(middleware.StructuredRequestLogger(*opts.RequestLog))
+	case opts.Logger != nil:
+		e.Use(middleware.RequestLogger(opts.Logger))
+	}
+	e.Use(echoMw.Recover())
+	e.Use(echoMw.CORSWithConfig(echoMw.CORSConfig{
+		AllowOrigins:     cfg.AllowOrigins(),
+		AllowCredentials: cfg.AllowCredentials,
+		AllowMethods: []string{
+			http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		AllowHeaders: []string{
+			echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept,
+			echo.HeaderAuthorization, opts.Trace.RequestIDHeader, "traceparent", "tracestate",
+		},
+		ExposeHeaders: []string{opts.Trace.RequestIDHeader, opts.Trace.TraceIDHeader},
+	}))
+	if cfg.BodyLimit != "" {
+		e.Use(echoMw.BodyLimitWithConfig(echoMw.BodyLimitConfig{
+			Limit:   cfg.BodyLimit,
+			Skipper: opts.BodyLimitSkipper,
+		}))
+	}
+	e.Use(opts.Middlewares...)
+
+	return e, nil
+}
+
+// SkipRoutes returns a skipper matching requests by route template (c.Path()), e.g. "/files/:id/upload".
+func SkipRoutes(routes ...string) func(c echo.Context) bool {
+	set := make(map[string]struct{}, len(routes))
+	for _, r := range routes {
+		set[r] = struct{}{}
+	}
+	return func(c echo.Context) bool {
+		_, ok := set[c.Path()]
+		return ok
+	}
+}
@@ -0,0 +1,25 @@
+package validators
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterTagNameFunc makes validator errors report fields by their tagKey name (e.g. "json"),
+// so FieldError.Namespace() contains wire names instead of Go field names.
+func RegisterTagNameFunc(v *validator.Validate, tagKey string) error {
+	if v == nil {
+		return errors.New("validator instance is nil")
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get(tagKey), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return nil
+}
@@ -0,0 +1,29 @@
+// Package logctx carries a request scoped *slog.Logger in context.Context so that code below the
+// HTTP layer logs with the same correlation fields (trace_id, user_id, ...) as the handler.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying l.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFrom returns the logger stored in ctx. Without one it falls back to slog.Default(),
+// tagged with trace_id when ctx carries a span (e.g. a consumed AMQP message).
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return slog.Default().With(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return slog.Default()
+}
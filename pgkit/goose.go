@@ -1,10 +1,14 @@
 package pgkit
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/nrf24l01/go-web-utils/config"
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
 )
 
 // ░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░
@@ -23,3 +27,37 @@ func RunMigrations(db *sql.DB, pg_cfg *config.PGConfig) error {
 
 	return nil
 }
+
+// MigrationVersions returns the version applied to db and the latest version in pg_cfg.Migrations.
+// The database is up to date when current >= latest. It only reads the goose version table, so it is
+// safe to call from health checks while RunMigrations runs; a missing table reports version 0.
+func MigrationVersions(ctx context.Context, db *sql.DB, pg_cfg *config.PGConfig) (current, latest int64, err error) {
+	store, err := database.NewStore(database.DialectPostgres, goose.DefaultTablename)
+	if err != nil {
+		return 0, 0, err
+	}
+	exists := true
+	if ext, ok := store.(database.StoreExtender); ok {
+		if exists, err = ext.TableExists(ctx, db); err != nil {
+			return 0, 0, fmt.Errorf("failed to read migration version: %w", err)
+		}
+	}
+	if exists {
+		current, err = store.GetLatestVersion(ctx, db)
+		if err != nil && !errors.Is(err, database.ErrVersionNotFound) {
+			return 0, 0, fmt.Errorf("failed to read migration version: %w", err)
+		}
+	}
+
+	migrations, err := goose.CollectMigrations(pg_cfg.Migrations, 0, goose.MaxVersion)
+	if err != nil {
+		if errors.Is(err, goose.ErrNoMigrationFiles) {
+			return current, 0, nil
+		}
+		return 0, 0, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+	if len(migrations) == 0 {
+		return current, 0, nil
+	}
+	return current, migrations[len(migrations)-1].Version, nil
+}
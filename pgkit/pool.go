@@ -3,10 +3,14 @@ package pgkit
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/nrf24l01/go-web-utils/config"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DB struct {
@@ -14,11 +18,37 @@ type DB struct {
 	SQL  *sql.DB
 }
 
+type Options struct {
+	// TracerProvider enables a span per query, batch and connect when set.
+	TracerProvider trace.TracerProvider
+	// SlowQueryThreshold logs slower queries with logctx.LoggerFrom(ctx), 0 disables it.
+	SlowQueryThreshold time.Duration
+}
+
 func NewDB(ctx context.Context, pg_cfg *config.PGConfig) (*DB, error) {
+	return NewDBWithOptions(ctx, pg_cfg, Options{})
+}
+
+func NewDBWithOptions(ctx context.Context, pg_cfg *config.PGConfig, opts Options) (*DB, error) {
 	cfg, err := pgxpool.ParseConfig(pg_cfg.GetDSN())
 	if err != nil {
 		return nil, err
 	}
+	applyPoolConfig(cfg, pg_cfg)
+	var tracers []pgx.QueryTracer
+	if opts.TracerProvider != nil {
+		tracers = append(tracers, newQueryTracer(opts.TracerProvider))
+	}
+	if opts.SlowQueryThreshold > 0 {
+		tracers = append(tracers, &slowQueryLogger{threshold: opts.SlowQueryThreshold})
+	}
+	switch len(tracers) {
+	case 0:
+	case 1:
+		cfg.ConnConfig.Tracer = tracers[0]
+	default:
+		cfg.ConnConfig.Tracer = multitracer.New(tracers...)
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
@@ -32,3 +62,22 @@ func NewDB(ctx context.Context, pg_cfg *config.PGConfig) (*DB, error) {
 		SQL:  sqlDB,
 	}, nil
 }
+
+// applyPoolConfig overrides pool sizing with the values set in PGConfig; zero values keep pgxpool defaults.
+func applyPoolConfig(cfg *pgxpool.Config, pg_cfg *config.PGConfig) {
+	if pg_cfg.PoolMaxConns > 0 {
+		cfg.MaxConns = pg_cfg.PoolMaxConns
+	}
+	if pg_cfg.PoolMinConns > 0 {
+		cfg.MinConns = pg_cfg.PoolMinConns
+	}
+	if pg_cfg.PoolMaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = pg_cfg.PoolMaxConnLifetime
+	}
+	if pg_cfg.PoolMaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = pg_cfg.PoolMaxConnIdleTime
+	}
+	if pg_cfg.PoolHealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = pg_cfg.PoolHealthCheckPeriod
+	}
+}
@@ -0,0 +1,135 @@
+package pgkit
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nrf24l01/go-web-utils/misc/logctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/nrf24l01/go-web-utils/pgkit"
+
+// queryTracer implements pgx.QueryTracer, pgx.BatchTracer and pgx.ConnectTracer with OpenTelemetry spans.
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func newQueryTracer(tp trace.TracerProvider) *queryTracer {
+	return &queryTracer{tracer: tp.Tracer(tracerName)}
+}
+
+func (t *queryTracer) start(ctx context.Context, name string, cfg *pgx.ConnConfig, attrs ...attribute.KeyValue) context.Context {
+	attrs = append(attrs, semconv.DBSystemPostgreSQL)
+	if cfg != nil {
+		attrs = append(attrs,
+			semconv.DBNamespace(cfg.Database),
+			semconv.ServerAddress(cfg.Host),
+			semconv.ServerPort(int(cfg.Port)),
+		)
+	}
+	ctx, _ = t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func endSpan(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func connConfig(conn *pgx.Conn) *pgx.ConnConfig {
+	if conn == nil {
+		return nil
+	}
+	return conn.Config()
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := queryOperation(data.SQL)
+	return t.start(ctx, op, connConfig(conn),
+		semconv.DBOperationName(op),
+		semconv.DBQueryText(data.SQL),
+	)
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("db.response.rows_affected", data.CommandTag.RowsAffected()))
+	endSpan(ctx, data.Err)
+}
+
+func (t *queryTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+	return t.start(ctx, "BATCH", connConfig(conn),
+		semconv.DBOperationName("BATCH"),
+		attribute.Int("db.operation.batch.size", size),
+	)
+}
+
+func (t *queryTracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	if data.Err != nil {
+		trace.SpanFromContext(ctx).RecordError(data.Err, trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+	}
+}
+
+func (t *queryTracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	endSpan(ctx, data.Err)
+}
+
+func (t *queryTracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	return t.start(ctx, "CONNECT", data.ConnConfig)
+}
+
+func (t *queryTracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	endSpan(ctx, data.Err)
+}
+
+// queryOperation returns the leading SQL keyword ("SELECT", "INSERT", ...) used as the span name.
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql   string
+	start time.Time
+}
+
+// slowQueryLogger logs queries slower than threshold with the logger carried by the query context.
+type slowQueryLogger struct {
+	threshold time.Duration
+}
+
+func (l *slowQueryLogger) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, start: time.Now()})
+}
+
+func (l *slowQueryLogger) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	qs, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	if elapsed := time.Since(qs.start); elapsed >= l.threshold {
+		logctx.LoggerFrom(ctx).LogAttrs(ctx, slog.LevelWarn, "PG_SLOW_QUERY",
+			slog.String("sql", qs.sql),
+			slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+		)
+	}
+}
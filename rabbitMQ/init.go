@@ -9,6 +9,10 @@ import (
 )
 
 func RegisterRabbitMQ(cfg *config.RabbitMQConfig) (*RabbitMQ, error) {
+	return RegisterRabbitMQWithOptions(cfg, Options{})
+}
+
+func RegisterRabbitMQWithOptions(cfg *config.RabbitMQConfig, opts Options) (*RabbitMQ, error) {
 	url := fmt.Sprintf("amqp://%s:%s@%s:%s/",
 		cfg.RabbitMQUser,
 		cfg.RabbitMQPassword,
@@ -30,6 +34,7 @@ func RegisterRabbitMQ(cfg *config.RabbitMQConfig) (*RabbitMQ, error) {
 		Conn:    conn,
 		Channel: channel,
 	}
+	rabbitMQ.setupTracing(opts)
 
 	return rabbitMQ, nil
 }
@@ -3,10 +3,23 @@ package rabbitMQ
 import (
 	"github.com/nrf24l01/go-web-utils/config"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RabbitMQ struct {
 	cfg     *config.RabbitMQConfig
 	Conn    *amqp.Connection
 	Channel *amqp.Channel
+
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+type Options struct {
+	// TracerProvider enables publish and consume spans when set.
+	TracerProvider trace.TracerProvider
+	// Propagator writes and reads the trace context in message headers, default W3C traceparent/tracestate.
+	// Setting it without TracerProvider propagates the incoming trace without creating spans.
+	Propagator propagation.TextMapPropagator
 }
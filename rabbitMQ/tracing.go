@@ -0,0 +1,162 @@
+package rabbitMQ
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/nrf24l01/go-web-utils/misc/logctx"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/nrf24l01/go-web-utils/rabbitMQ"
+
+func (r *RabbitMQ) setupTracing(opts Options) {
+	if opts.TracerProvider == nil && opts.Propagator == nil {
+		return
+	}
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	r.tracer = tp.Tracer(tracerName)
+	r.propagator = opts.Propagator
+	if r.propagator == nil {
+		r.propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+}
+
+// HeaderCarrier adapts amqp.Table to propagation.TextMapCarrier.
+type HeaderCarrier amqp.Table
+
+func (h HeaderCarrier) Get(key string) string {
+	switch v := h[key].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+func (h HeaderCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Publish sends msg like Channel.PublishWithContext. With tracing enabled it starts a producer span
+// and writes the trace context into msg.Headers so consumers continue the same trace.
+func (r *RabbitMQ) Publish(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	var span trace.Span
+	if r.tracer != nil {
+		ctx, span = r.tracer.Start(ctx, "publish "+destination(exchange, key),
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(messageAttrs(exchange, key, msg.MessageId, msg.CorrelationId)...),
+			trace.WithAttributes(semconv.MessagingOperationTypePublish),
+		)
+		defer span.End()
+
+		headers := make(amqp.Table, len(msg.Headers)+2)
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+		r.propagator.Inject(ctx, HeaderCarrier(headers))
+		msg.Headers = headers
+	}
+
+	if err := r.Channel.PublishWithContext(ctx, exchange, key, mandatory, immediate, msg); err != nil {
+		logctx.LoggerFrom(ctx).LogAttrs(ctx, slog.LevelError, "AMQP_PUBLISH_FAILED",
+			slog.String("exchange", exchange),
+			slog.String("routing_key", key),
+			slog.String("error", err.Error()),
+		)
+		if span != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// HandleDelivery runs fn for one consumed message with a logger (logctx.LoggerFrom) tagged with the
+// message. With tracing enabled the trace context is read from the message headers and fn runs inside
+// a consumer span that continues the publisher's trace; the logger then carries its trace_id too.
+func (r *RabbitMQ) HandleDelivery(ctx context.Context, d amqp.Delivery, fn func(ctx context.Context, d amqp.Delivery) error) error {
+	if r.tracer == nil {
+		return r.runDelivery(ctx, d, fn)
+	}
+
+	if d.Headers != nil {
+		ctx = r.propagator.Extract(ctx, HeaderCarrier(d.Headers))
+	}
+	ctx, span := r.tracer.Start(ctx, "process "+destination(d.Exchange, d.RoutingKey),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messageAttrs(d.Exchange, d.RoutingKey, d.MessageId, d.CorrelationId)...),
+		trace.WithAttributes(
+			semconv.MessagingOperationTypeDeliver,
+			attribute.String("messaging.consumer.group.name", d.ConsumerTag),
+		),
+	)
+	defer span.End()
+
+	err := r.runDelivery(ctx, d, fn)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (r *RabbitMQ) runDelivery(ctx context.Context, d amqp.Delivery, fn func(ctx context.Context, d amqp.Delivery) error) error {
+	attrs := []any{slog.String("routing_key", d.RoutingKey)}
+	if d.Exchange != "" {
+		attrs = append(attrs, slog.String("exchange", d.Exchange))
+	}
+	if d.MessageId != "" {
+		attrs = append(attrs, slog.String("message_id", d.MessageId))
+	}
+	logger := logctx.LoggerFrom(ctx).With(attrs...)
+	ctx = logctx.WithLogger(ctx, logger)
+
+	err := fn(ctx, d)
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelWarn, "AMQP_DELIVERY_FAILED", slog.String("error", err.Error()))
+	}
+	return err
+}
+
+func destination(exchange, key string) string {
+	if exchange == "" {
+		return key
+	}
+	return exchange
+}
+
+func messageAttrs(exchange, key, messageID, correlationID string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(destination(exchange, key)),
+		semconv.MessagingRabbitmqDestinationRoutingKey(key),
+	}
+	if messageID != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(messageID))
+	}
+	if correlationID != "" {
+		attrs = append(attrs, semconv.MessagingMessageConversationID(correlationID))
+	}
+	return attrs
+}
@@ -2,10 +2,12 @@ package redis
 
 import (
 	"context"
+	"fmt"
 	"log"
 
 	"github.com/nrf24l01/go-web-utils/config"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RedisClient struct {
@@ -13,20 +15,43 @@ type RedisClient struct {
 	Ctx    context.Context
 }
 
+type Options struct {
+	// TracerProvider enables a span per command, pipeline and dial when set.
+	TracerProvider trace.TracerProvider
+}
+
 func NewRedisClient(config *config.RedisConfig) *RedisClient {
-	rdb := redis.NewClient(&redis.Options{
+	return NewRedisClientWithOptions(config, Options{})
+}
+
+func NewRedisClientWithOptions(config *config.RedisConfig, opts Options) *RedisClient {
+	client, err := Connect(context.Background(), config, opts)
+	if err != nil {
+		log.Fatalf("Не удалось подключиться к Redis: %v", err)
+	}
+	return client
+}
+
+// Connect creates the client and pings Redis within ctx. Unlike NewRedisClientWithOptions it returns
+// the error instead of exiting, so callers can shut down what they already started.
+func Connect(ctx context.Context, config *config.RedisConfig, opts Options) (*RedisClient, error) {
+	redisOpts := &redis.Options{
 		Addr:     config.RedisHost,
 		Password: config.RedisPassword,
 		DB:       config.RedisDB,
-	})
+	}
+	rdb := redis.NewClient(redisOpts)
+	if opts.TracerProvider != nil {
+		rdb.AddHook(newTracingHook(opts.TracerProvider, redisOpts))
+	}
 
-	ctx := context.Background()
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		log.Fatalf("Не удалось подключиться к Redis: %v", err)
+		rdb.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
 	return &RedisClient{
 		Client: rdb,
-		Ctx:    ctx,
-	}
+		Ctx:    context.Background(),
+	}, nil
 }
@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/nrf24l01/go-web-utils/redis"
+
+// tracingHook is a redis.Hook that wraps commands, pipelines and dials in OpenTelemetry spans.
+type tracingHook struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+func newTracingHook(tp trace.TracerProvider, opts *redis.Options) *tracingHook {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemRedis,
+		semconv.DBNamespace(strconv.Itoa(opts.DB)),
+	}
+	if host, port, err := net.SplitHostPort(opts.Addr); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(p))
+		}
+	}
+	return &tracingHook{tracer: tp.Tracer(tracerName), attrs: attrs}
+}
+
+func (h *tracingHook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return h.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (h *tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := h.start(ctx, "redis.dial")
+		conn, err := next(ctx, network, addr)
+		endSpan(span, err)
+		return conn, err
+	}
+}
+
+func (h *tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		name := strings.ToUpper(cmd.Name())
+		ctx, span := h.start(ctx, name, semconv.DBOperationName(name))
+		err := next(ctx, cmd)
+		endSpan(span, err)
+		return err
+	}
+}
+
+func (h *tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		names := make([]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			names = append(names, strings.ToUpper(cmd.Name()))
+		}
+		ctx, span := h.start(ctx, "PIPELINE",
+			semconv.DBOperationName("PIPELINE"),
+			attribute.StringSlice("db.redis.commands", names),
+			attribute.Int("db.operation.batch.size", len(cmds)),
+		)
+		err := next(ctx, cmds)
+		endSpan(span, err)
+		return err
+	}
+}
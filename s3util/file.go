@@ -1,10 +1,13 @@
 package s3util
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"io"
 	"mime/multipart"
+
+	"github.com/google/uuid"
 )
 
 func FileHashSHA256(fileHeader *multipart.FileHeader) (string, error) {
@@ -21,3 +24,28 @@ func FileHashSHA256(fileHeader *multipart.FileHeader) (string, error) {
 
 	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
+
+// UploadSink streams uploaded files into a bucket. It satisfies middleware.FileSink.
+type UploadSink struct {
+	Client *Client
+	Bucket string
+	// ObjectName builds the object key from the client file name; a random UUID is used when nil.
+	ObjectName func(fileName string) string
+}
+
+// Store uploads r and returns the object name.
+func (s *UploadSink) Store(ctx context.Context, fileName, contentType string, r io.Reader) (string, error) {
+	objectName := uuid.New().String()
+	if s.ObjectName != nil {
+		objectName = s.ObjectName(fileName)
+	}
+	if _, err := s.Client.UploadStream(ctx, s.Bucket, objectName, r, -1, contentType); err != nil {
+		return "", err
+	}
+	return objectName, nil
+}
+
+// Remove deletes a previously stored object, used to roll back a partially processed request.
+func (s *UploadSink) Remove(ctx context.Context, objectName string) error {
+	return s.Client.RemoveFile(ctx, s.Bucket, objectName)
+}
@@ -12,14 +12,26 @@ import (
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/nrf24l01/go-web-utils/config"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type Client struct {
 	minio   *minio.Client
 	baseURL string
+	tracer  trace.Tracer
+}
+
+type Options struct {
+	// TracerProvider enables a span per client call when set.
+	TracerProvider trace.TracerProvider
 }
 
 func New(cfg config.S3Config) (*Client, error) {
+	return NewWithOptions(cfg, Options{})
+}
+
+func NewWithOptions(cfg config.S3Config, opts Options) (*Client, error) {
 	if cfg.Endpoint == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
 		return nil, fmt.Errorf("endpoint, accessKey and secretKey must be set")
 	}
@@ -30,11 +42,18 @@ func New(cfg config.S3Config) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Client{minio: client, baseURL: cfg.BaseURL}, nil
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return &Client{minio: client, baseURL: cfg.BaseURL, tracer: tp.Tracer(tracerName)}, nil
 }
 
-func (c *Client) GeneratePresignedPutURL(ctx context.Context, bucket string, expires time.Duration) (string, string, error) {
+func (c *Client) GeneratePresignedPutURL(ctx context.Context, bucket string, expires time.Duration) (_ string, _ string, err error) {
 	uniqueID := uuid.New().String()
+	ctx, call := c.startCall(ctx, "PresignedPutObject", bucket, uniqueID)
+	defer func() { call.end(err) }()
+
 	presignedURL, err := c.minio.PresignedPutObject(ctx, bucket, uniqueID, expires)
 	if err != nil {
 		return "", "", fmt.Errorf("generate presigned PUT url: %w", err)
@@ -46,7 +65,10 @@ func (c *Client) GeneratePresignedPutURL(ctx context.Context, bucket string, exp
 	return uniqueID, urlStr, nil
 }
 
-func (c *Client) GeneratePresignedGetURL(ctx context.Context, bucket, object string, expires time.Duration) (string, error) {
+func (c *Client) GeneratePresignedGetURL(ctx context.Context, bucket, object string, expires time.Duration) (_ string, err error) {
+	ctx, call := c.startCall(ctx, "PresignedGetObject", bucket, object)
+	defer func() { call.end(err) }()
+
 	presignedURL, err := c.minio.PresignedGetObject(ctx, bucket, object, expires, nil)
 	if err != nil {
 		return "", fmt.Errorf("generate presigned GET url: %w", err)
@@ -68,8 +90,11 @@ func (c *Client) GetPermanentObjectURL(bucket, object string) string {
 	return fmt.Sprintf("%s/%s/%s", c.baseURL, bucket, object)
 }
 
-func (c *Client) UploadFile(ctx context.Context, bucket, objectName string, data []byte, contentType string) error {
-	_, err := c.minio.PutObject(ctx, bucket, objectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+func (c *Client) UploadFile(ctx context.Context, bucket, objectName string, data []byte, contentType string) (err error) {
+	ctx, call := c.startCall(ctx, "PutObject", bucket, objectName)
+	defer func() { call.end(err) }()
+
+	_, err = c.minio.PutObject(ctx, bucket, objectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
 		ContentType: contentType,
 	})
 	if err != nil {
@@ -78,7 +103,10 @@ func (c *Client) UploadFile(ctx context.Context, bucket, objectName string, data
 	return nil
 }
 
-func (c *Client) DownloadFile(ctx context.Context, bucket, objectName string) ([]byte, error) {
+func (c *Client) DownloadFile(ctx context.Context, bucket, objectName string) (_ []byte, err error) {
+	ctx, call := c.startCall(ctx, "GetObject", bucket, objectName)
+	defer func() { call.end(err) }()
+
 	obj, err := c.minio.GetObject(ctx, bucket, objectName, minio.GetObjectOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("download file: %w", err)
@@ -117,3 +145,44 @@ func replaceHostWithBaseURL(originalURL string, baseURL string) (string, error)
 	}
 	return u.String(), nil
 }
+
+// streamPartSize bounds memory used by UploadStream when the object size is unknown.
+const streamPartSize = 16 << 20
+
+// UploadStream uploads r without buffering it whole. With size < 0 a multipart upload is used,
+// holding at most one 16MiB part in memory.
+func (c *Client) UploadStream(ctx context.Context, bucket, objectName string, r io.Reader, size int64, contentType string) (_ minio.UploadInfo, err error) {
+	ctx, call := c.startCall(ctx, "PutObject", bucket, objectName)
+	defer func() { call.end(err) }()
+
+	info, err := c.minio.PutObject(ctx, bucket, objectName, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    streamPartSize,
+	})
+	if err != nil {
+		return info, fmt.Errorf("upload stream: %w", err)
+	}
+	return info, nil
+}
+
+// BucketExists reports whether bucket exists and is accessible with the client's credentials.
+func (c *Client) BucketExists(ctx context.Context, bucket string) (_ bool, err error) {
+	ctx, call := c.startCall(ctx, "HeadBucket", bucket, "")
+	defer func() { call.end(err) }()
+
+	exists, err := c.minio.BucketExists(ctx, bucket)
+	if err != nil {
+		return false, fmt.Errorf("check bucket: %w", err)
+	}
+	return exists, nil
+}
+
+func (c *Client) RemoveFile(ctx context.Context, bucket, objectName string) (err error) {
+	ctx, call := c.startCall(ctx, "RemoveObject", bucket, objectName)
+	defer func() { call.end(err) }()
+
+	if err := c.minio.RemoveObject(ctx, bucket, objectName, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("remove file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package s3util
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/nrf24l01/go-web-utils/misc/logctx"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/nrf24l01/go-web-utils/s3util"
+
+// call is one traced client operation; end records its outcome on the span and logs failures
+// with logctx.LoggerFrom(ctx).
+type call struct {
+	ctx       context.Context
+	span      trace.Span
+	operation string
+	bucket    string
+	object    string
+}
+
+func (c *Client) startCall(ctx context.Context, operation, bucket, object string) (context.Context, *call) {
+	ctx, span := c.tracer.Start(ctx, "S3."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("aws-api"),
+			semconv.RPCService("S3"),
+			semconv.RPCMethod(operation),
+			semconv.AWSS3Bucket(bucket),
+			semconv.AWSS3Key(object),
+			semconv.ServerAddress(c.minio.EndpointURL().Hostname()),
+		),
+	)
+	return ctx, &call{ctx: ctx, span: span, operation: operation, bucket: bucket, object: object}
+}
+
+func (o *call) end(err error) {
+	if err != nil {
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+		logctx.LoggerFrom(o.ctx).LogAttrs(o.ctx, slog.LevelWarn, "S3_REQUEST_FAILED",
+			slog.String("operation", o.operation),
+			slog.String("bucket", o.bucket),
+			slog.String("object", o.object),
+			slog.String("error", err.Error()),
+		)
+	}
+	o.span.End()
+}